@@ -0,0 +1,160 @@
+package depgraph
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Attributes are free-form values attached to a node or a link
+type Attributes map[string]any
+
+// Well known attribute keys, the bpmn package sets these when loading a diagram
+const (
+	AttrLabel     = "label"     // Display name of the node or link
+	AttrType      = "type"      // Kind of node, e.g. task, exclusiveGateway, endEvent
+	AttrLane      = "lane"      // Lane or swim-lane the node belongs to
+	AttrDuration  = "duration"  // How long the node takes, a time.Duration
+	AttrDelay     = "delay"     // Wait on a link before the next node can start, a time.Duration
+	AttrCondition = "condition" // Condition expression on a link
+	AttrRetries   = "retries"   // Times Execute retries the node, an int
+	AttrTimeout   = "timeout"   // How long Execute gives the node each attempt, a time.Duration
+)
+
+// durationAttr converts an attribute value to a time.Duration.  As well as a time.Duration it takes the
+// whole nanoseconds JSON and GraphML load it back as and strings such as "5m0s", a missing value is 0
+func durationAttr(value any) (time.Duration, error) {
+	if s, ok := value.(string); ok {
+		return time.ParseDuration(s)
+	}
+	n, err := intAttr(value)
+	if err != nil {
+		return 0, fmt.Errorf("%v is not a duration", value)
+	}
+	return time.Duration(n), nil
+}
+
+// intAttr converts a whole number attribute value, or a string of one, to an int, a missing value is 0
+func intAttr(value any) (int, error) {
+	if value == nil {
+		return 0, nil
+	}
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return int(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := v.Uint(); u <= math.MaxInt {
+			return int(u), nil
+		}
+	case reflect.Float32, reflect.Float64:
+		// 2^63 is exact as a float64 but one more than the largest int64
+		if f := v.Float(); f == math.Trunc(f) && math.Abs(f) < math.MaxInt64 {
+			return int(f), nil
+		}
+	case reflect.String:
+		return strconv.Atoi(v.String())
+	}
+	return 0, fmt.Errorf("%v is not a whole number", value)
+}
+
+func (a Attributes) clone() Attributes {
+	if a == nil {
+		return nil
+	}
+	return maps.Clone(a)
+}
+
+// SetNodeAttr sets an attribute on an existing node
+func (g *Graph[K]) SetNodeAttr(id K, key string, value any) error {
+	n, ok := g.nodes[id]
+	if !ok {
+		return fmt.Errorf("node %v not found", id)
+	}
+	if n.attrs == nil {
+		n.attrs = make(Attributes)
+	}
+	n.attrs[key] = value
+	return nil
+}
+
+// NodeAttr returns the value of an attribute on a node and whether it was set
+func (g *Graph[K]) NodeAttr(id K, key string) (value any, ok bool) {
+	if n, inGraph := g.nodes[id]; inGraph {
+		value, ok = n.attrs[key]
+	}
+	return value, ok
+}
+
+// NodeAttrs returns a copy of all the attributes on a node
+func (g *Graph[K]) NodeAttrs(id K) Attributes {
+	if n, ok := g.nodes[id]; ok {
+		return n.attrs.clone()
+	}
+	return nil
+}
+
+// SetLinkAttr sets an attribute on the first link between two nodes, the nodes must already depend on
+// each other.  Use SetLinkAttrByID when there are several links between the nodes
+func (g *Graph[K]) SetLinkAttr(from, to K, key string, value any) error {
+	if _, ok := g.dependentMap[from][to]; !ok {
+		return fmt.Errorf("no link from %v to %v", from, to)
+	}
+	l := g.firstLink(from, to)
+	if l == nil {
+		l, _ = g.addLink(from, to, "") // No ID so it can't clash
+	}
+	l.setAttr(key, value)
+	return nil
+}
+
+// LinkAttr returns the value of an attribute on the first link between two nodes and whether it was set
+func (g *Graph[K]) LinkAttr(from, to K, key string) (value any, ok bool) {
+	if l := g.firstLink(from, to); l != nil {
+		value, ok = l.attrs[key]
+	}
+	return value, ok
+}
+
+// LinkAttrs returns a copy of all the attributes on the first link between two nodes
+func (g *Graph[K]) LinkAttrs(from, to K) Attributes {
+	if l := g.firstLink(from, to); l != nil {
+		return l.attrs.clone()
+	}
+	return nil
+}
+
+// SetLinkAttrByID sets an attribute on the link with linkID
+func (g *Graph[K]) SetLinkAttrByID(linkID string, key string, value any) error {
+	_, _, l, found := g.findLink(linkID)
+	if !found {
+		return fmt.Errorf("link %v not found", linkID)
+	}
+	l.setAttr(key, value)
+	return nil
+}
+
+// LinkAttrsByID returns a copy of all the attributes on the link with linkID
+func (g *Graph[K]) LinkAttrsByID(linkID string) Attributes {
+	if _, _, l, found := g.findLink(linkID); found {
+		return l.attrs.clone()
+	}
+	return nil
+}
+
+func (g *Graph[K]) firstLink(from, to K) *link {
+	if links := g.linkMap[from][to]; len(links) > 0 {
+		return links[0]
+	}
+	return nil
+}
+
+func (l *link) setAttr(key string, value any) {
+	if l.attrs == nil {
+		l.attrs = make(Attributes)
+	}
+	l.attrs[key] = value
+}
@@ -0,0 +1,54 @@
+package depgraph_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestAttributes(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("1", "start", "check"))
+	assert.NoError(t, g.AddLink("2", "check", "end"))
+	assert.Error(t, g.SetNodeAttr("missing", depgraph.AttrLabel, "Missing"))
+	assert.Error(t, g.SetLinkAttr("start", "end", depgraph.AttrLabel, "Missing"))
+
+	assert.NoError(t, g.SetNodeAttr("check", depgraph.AttrLabel, "Check Order"))
+	assert.NoError(t, g.SetNodeAttr("check", depgraph.AttrDuration, 5*time.Minute))
+	assert.NoError(t, g.SetLinkAttr("check", "end", depgraph.AttrCondition, "valid"))
+
+	label, ok := g.NodeAttr("check", depgraph.AttrLabel)
+	assert.True(t, ok)
+	assert.Equal(t, "Check Order", label)
+	_, ok = g.NodeAttr("start", depgraph.AttrLabel)
+	assert.False(t, ok)
+	assert.Nil(t, g.NodeAttrs("start"))
+
+	// Moving a node keeps its attributes and its place in the add order
+	nodes := g.Nodes()
+	g.AddNode("check", 10, 20)
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrLabel: "Check Order", depgraph.AttrDuration: 5 * time.Minute}, g.NodeAttrs("check"))
+	assert.Equal(t, nodes, g.Nodes())
+
+	// Attributes returned are copies
+	g.NodeAttrs("check")[depgraph.AttrLabel] = "Changed"
+	label, _ = g.NodeAttr("check", depgraph.AttrLabel)
+	assert.Equal(t, "Check Order", label)
+
+	actual := g.TopologicalSort()
+	assert.Len(t, actual, 3)
+	assert.Equal(t, "Check Order", actual[1].Attributes[depgraph.AttrLabel])
+	assert.Equal(t, "valid", actual[2].LinkAttributes[depgraph.AttrCondition])
+	assert.Equal(t, "2", actual[2].FromLinkID)
+
+	// Attributes can go on a link without an ID
+	assert.NoError(t, g.DependOn("audit", "end"))
+	assert.NoError(t, g.SetLinkAttr("end", "audit", depgraph.AttrLabel, "Audit"))
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrLabel: "Audit"}, g.LinkAttrs("end", "audit"))
+	assert.NoError(t, g.AddLink("3", "end", "audit"))
+	assert.Error(t, g.RemoveLinkByID(""))
+	assert.NoError(t, g.RemoveLinkByID("3"))
+	assert.Nil(t, g.LinkAttrs("end", "audit"))
+}
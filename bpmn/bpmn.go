@@ -0,0 +1,180 @@
+// Package bpmn builds a depgraph.Graph from a BPMN 2.0 XML process diagram
+package bpmn
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/timdadd/depgraph"
+)
+
+// flowNodes are the BPMN elements that become nodes in the graph, anything else in a process
+// (lanes, documentation, annotations etc.) is ignored
+var flowNodes = map[string]bool{
+	"task":                   true,
+	"userTask":               true,
+	"serviceTask":            true,
+	"sendTask":               true,
+	"receiveTask":            true,
+	"manualTask":             true,
+	"scriptTask":             true,
+	"businessRuleTask":       true,
+	"callActivity":           true,
+	"subProcess":             true,
+	"exclusiveGateway":       true,
+	"inclusiveGateway":       true,
+	"parallelGateway":        true,
+	"eventBasedGateway":      true,
+	"complexGateway":         true,
+	"startEvent":             true,
+	"endEvent":               true,
+	"intermediateThrowEvent": true,
+	"intermediateCatchEvent": true,
+	"boundaryEvent":          true,
+}
+
+type definitions struct {
+	Processes []process `xml:"process"`
+	Diagrams  []diagram `xml:"BPMNDiagram"`
+}
+
+type process struct {
+	ID       string    `xml:"id,attr"`
+	LaneSets []laneSet `xml:"laneSet"`
+	Elements []element `xml:",any"`
+}
+
+// element is any child of a process, XMLName tells us what it is
+type element struct {
+	XMLName             xml.Name
+	ID                  string `xml:"id,attr"`
+	Name                string `xml:"name,attr"`
+	SourceRef           string `xml:"sourceRef,attr"`
+	TargetRef           string `xml:"targetRef,attr"`
+	ConditionExpression string `xml:"conditionExpression"`
+}
+
+type laneSet struct {
+	Lanes []lane `xml:"lane"`
+}
+
+type lane struct {
+	Name         string   `xml:"name,attr"`
+	FlowNodeRefs []string `xml:"flowNodeRef"`
+	ChildLaneSet *laneSet `xml:"childLaneSet"`
+}
+
+// addLanes records the lane of each flow node, a node in a child lane gets the child lane name
+func (ls *laneSet) addLanes(lanes map[string]string) {
+	for _, l := range ls.Lanes {
+		for _, ref := range l.FlowNodeRefs {
+			lanes[ref] = l.Name
+		}
+		if l.ChildLaneSet != nil {
+			l.ChildLaneSet.addLanes(lanes)
+		}
+	}
+}
+
+type diagram struct {
+	Planes []plane `xml:"BPMNPlane"`
+}
+
+type plane struct {
+	Shapes []shape `xml:"BPMNShape"`
+}
+
+// shape is the DI for an element, the Bounds can be dc:Bounds or Bounds in the DC namespace
+type shape struct {
+	BPMNElement string `xml:"bpmnElement,attr"`
+	Bounds      struct {
+		X float32 `xml:"x,attr"`
+		Y float32 `xml:"y,attr"`
+	} `xml:"Bounds"`
+}
+
+// Load reads a BPMN 2.0 document and returns a graph of every process in it.
+// Flow nodes become graph nodes positioned using the BPMNShape bounds and each
+// sequenceFlow becomes a link using the sequenceFlow id as the linkID.
+// Nodes get the AttrType, AttrLabel and AttrLane attributes and links
+// the AttrLabel and AttrCondition attributes, when they are set in the diagram
+func Load(r io.Reader) (*depgraph.Graph[string], error) {
+	return load(r, "")
+}
+
+// LoadProcess is like Load but only the process with the given id is added to the graph
+func LoadProcess(r io.Reader, processID string) (*depgraph.Graph[string], error) {
+	return load(r, processID)
+}
+
+func load(r io.Reader, processID string) (g *depgraph.Graph[string], err error) {
+	var defs definitions
+	if err = xml.NewDecoder(r).Decode(&defs); err != nil {
+		return nil, fmt.Errorf("decoding bpmn: %w", err)
+	}
+
+	type position struct{ x, y float32 }
+	positions := make(map[string]position)
+	for _, d := range defs.Diagrams {
+		for _, p := range d.Planes {
+			for _, s := range p.Shapes {
+				positions[s.BPMNElement] = position{x: s.Bounds.X, y: s.Bounds.Y}
+			}
+		}
+	}
+
+	g = depgraph.NewGraph[string]()
+	found := processID == ""
+	for _, p := range defs.Processes {
+		if processID != "" && p.ID != processID {
+			continue
+		}
+		found = true
+		lanes := make(map[string]string)
+		for _, ls := range p.LaneSets {
+			ls.addLanes(lanes)
+		}
+		// Add the nodes first so the sequence flows can be checked
+		nodes := make(map[string]bool)
+		for _, e := range p.Elements {
+			if flowNodes[e.XMLName.Local] {
+				pos := positions[e.ID]
+				g.AddNode(e.ID, pos.x, pos.y)
+				nodes[e.ID] = true
+				_ = g.SetNodeAttr(e.ID, depgraph.AttrType, e.XMLName.Local)
+				if e.Name != "" {
+					_ = g.SetNodeAttr(e.ID, depgraph.AttrLabel, e.Name)
+				}
+				if l, inLane := lanes[e.ID]; inLane {
+					_ = g.SetNodeAttr(e.ID, depgraph.AttrLane, l)
+				}
+			}
+		}
+		for _, e := range p.Elements {
+			if e.XMLName.Local != "sequenceFlow" {
+				continue
+			}
+			if !nodes[e.SourceRef] {
+				return nil, fmt.Errorf("sequenceFlow %v has unknown source %v", e.ID, e.SourceRef)
+			}
+			if !nodes[e.TargetRef] {
+				return nil, fmt.Errorf("sequenceFlow %v has unknown target %v", e.ID, e.TargetRef)
+			}
+			if err = g.AddLink(e.ID, e.SourceRef, e.TargetRef); err != nil {
+				return nil, err
+			}
+			if e.Name != "" {
+				_ = g.SetLinkAttrByID(e.ID, depgraph.AttrLabel, e.Name)
+			}
+			if condition := strings.TrimSpace(e.ConditionExpression); condition != "" {
+				_ = g.SetLinkAttrByID(e.ID, depgraph.AttrCondition, condition)
+			}
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("process %v not found", processID)
+	}
+	return g, nil
+}
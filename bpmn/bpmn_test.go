@@ -0,0 +1,75 @@
+package bpmn_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+	"github.com/timdadd/depgraph/bpmn"
+)
+
+func TestLoadProcess(t *testing.T) {
+	f, err := os.Open("TestTopologicalSort005.xml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	g, err := bpmn.LoadProcess(f, "Id_b3f1ea0f-cf74-44d2-bdbb-925b41e5d68b")
+	require.NoError(t, err)
+	assert.Len(t, g.Nodes(), 55)
+	assert.True(t, g.DependsOn("Id_9803dd09-4618-4d4f-9a36-7fb2247d1e74", "Event_156e4wi"))
+	assert.True(t, g.DependsOn("Event_1b30bns", "Event_156e4wi"))
+
+	assert.Equal(t, depgraph.Attributes{
+		depgraph.AttrType:  "exclusiveGateway",
+		depgraph.AttrLabel: "Order requires Logistics SIM Card delivery?",
+		depgraph.AttrLane:  "HOBS",
+	}, g.NodeAttrs("Gateway_1icfqwu"))
+	label, ok := g.LinkAttr("Gateway_1icfqwu", "Activity_1r47mqk", depgraph.AttrLabel)
+	assert.True(t, ok)
+	assert.Equal(t, "Yes", label)
+
+	actual := g.TopologicalSort()
+	assert.Len(t, actual, 55)
+	assert.Equal(t, "Event_156e4wi", actual[0].Node)
+	assert.Equal(t, "0001", actual[0].SortedStep)
+	assert.Equal(t, "", actual[0].FromLinkID)
+	assert.Equal(t, "Id_9803dd09-4618-4d4f-9a36-7fb2247d1e74", actual[1].Node)
+	assert.Equal(t, "Flow_18p2usn", actual[1].FromLinkID)
+}
+
+func TestLoad(t *testing.T) {
+	f, err := os.Open("TestTopologicalSort005.xml")
+	require.NoError(t, err)
+	defer f.Close()
+
+	g, err := bpmn.Load(f)
+	require.NoError(t, err)
+	assert.True(t, g.DependsOn("Gateway_0y6y0bg", "Event_1qfu6cb"))
+	assert.True(t, g.DependsOn("Event_1b30bns", "Event_156e4wi"))
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, err := bpmn.LoadProcess(strings.NewReader(`<definitions><process id="p1"/></definitions>`), "p2")
+	assert.Error(t, err)
+
+	_, err = bpmn.Load(strings.NewReader(`<definitions><process id="p1">
+		<task id="a"/>
+		<sequenceFlow id="f1" sourceRef="a" targetRef="b"/>
+	</process></definitions>`))
+	assert.Error(t, err)
+
+	g, err := bpmn.Load(strings.NewReader(`<definitions><process id="p1">
+		<exclusiveGateway id="a"/>
+		<task id="b"/>
+		<sequenceFlow id="f1" sourceRef="a" targetRef="b"><conditionExpression> ok </conditionExpression></sequenceFlow>
+	</process></definitions>`))
+	assert.NoError(t, err)
+	condition, _ := g.LinkAttr("a", "b", depgraph.AttrCondition)
+	assert.Equal(t, "ok", condition)
+
+	_, err = bpmn.Load(strings.NewReader(`<definitions>`))
+	assert.Error(t, err)
+}
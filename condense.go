@@ -0,0 +1,62 @@
+package depgraph
+
+import "fmt"
+
+// Component is a node of a condensed graph standing in for a strongly connected component,
+// Nodes are the members of the component in the order they were added to the original graph
+type Component[K comparable] struct {
+	Nodes []K
+}
+
+func (c *Component[K]) String() string {
+	if len(c.Nodes) == 1 {
+		return fmt.Sprint(c.Nodes[0])
+	}
+	return fmt.Sprint(c.Nodes)
+}
+
+// StronglyConnectedComponents returns every strongly connected component in the graph, including those
+// with a single node.  Components and their nodes are in the order they were added
+func (g *Graph[K]) StronglyConnectedComponents() [][]K {
+	return g.stronglyConnected()
+}
+
+// Condense returns a new graph from g where each strongly connected component is collapsed into a single
+// *Component node, nodes not on a cycle become a component of one.  The condensed graph has no cycles
+// so SortedLayers and TopologicalSort include every node, use Expand to get back the members.
+// A component takes the co-ordinates of its first member and links inside a component are dropped.
+// It's a function rather than a method as Graph[K] can't refer to Graph[*Component[K]]
+func Condense[K comparable](g *Graph[K]) *Graph[*Component[K]] {
+	condensed := NewGraph[*Component[K]]()
+	components := make(map[K]*Component[K], len(g.nodes))
+	for _, nodes := range g.stronglyConnected() {
+		c := &Component[K]{Nodes: nodes}
+		for _, n := range nodes {
+			components[n] = c
+		}
+		first := g.nodes[nodes[0]]
+		condensed.AddNode(c, first.x, first.y)
+	}
+	for _, parent := range g.nodesInAddOrder() {
+		from := components[parent]
+		for _, child := range g.inAddOrder(g.dependentMap[parent]) {
+			to := components[child]
+			if from == to {
+				continue
+			}
+			_ = condensed.DependOn(to, from) // Can't fail as from != to
+			for _, id := range g.linkIDs(parent, child) {
+				_, _ = condensed.addLink(from, to, id) // IDs are already unique in g
+			}
+		}
+	}
+	return condensed
+}
+
+// Expand returns the members of each component in turn, use it on the output of a condensed graph
+func Expand[K comparable](components []*Component[K]) (expanded []K) {
+	for _, c := range components {
+		expanded = append(expanded, c.Nodes...)
+	}
+	return expanded
+}
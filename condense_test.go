@@ -0,0 +1,53 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestCondense(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.AddLink("1", "start", "refresh"))
+	assert.NoError(t, g.AddLink("2", "refresh", "snapshot"))
+	assert.NoError(t, g.AddLink("3", "snapshot", "refresh"))
+	assert.NoError(t, g.AddLink("4", "snapshot", "end"))
+
+	assert.Equal(t, [][]any{{"start"}, {"refresh", "snapshot"}, {"end"}}, g.StronglyConnectedComponents())
+
+	c := depgraph.Condense(g)
+	assert.Len(t, c.Nodes(), 3)
+	assert.Empty(t, c.Cycles())
+
+	layers := c.SortedLayers()
+	assert.Len(t, layers, 3)
+	assert.Equal(t, []any{"start"}, layers[0][0].Nodes)
+	assert.Equal(t, []any{"refresh", "snapshot"}, layers[1][0].Nodes)
+	assert.Equal(t, []any{"end"}, layers[2][0].Nodes)
+	assert.Equal(t, []any{"start", "refresh", "snapshot", "end"}, depgraph.Expand(c.Sorted()))
+
+	actual := c.TopologicalSort()
+	assert.Len(t, actual, 3)
+	assert.Equal(t, "2", actual[1].Step)
+	assert.Equal(t, "1", actual[1].FromLinkID)
+	assert.Equal(t, "[refresh snapshot]", actual[1].Node.String())
+	assert.Equal(t, "end", actual[2].Node.String())
+	assert.Equal(t, "4", actual[2].FromLinkID)
+}
+
+func TestCondenseLinkOrder(t *testing.T) {
+	// Links from start into the same component keep the order they were added in
+	for range 20 {
+		g := depgraph.New()
+		assert.NoError(t, g.AddLink("1", "start", "refresh"))
+		assert.NoError(t, g.AddLink("2", "refresh", "snapshot"))
+		assert.NoError(t, g.AddLink("3", "snapshot", "refresh"))
+		assert.NoError(t, g.AddLink("9", "start", "snapshot"))
+
+		actual := depgraph.Condense(g).TopologicalSort()
+		assert.Len(t, actual, 2)
+		assert.Equal(t, "1", actual[1].FromLinkID)
+		assert.Equal(t, []string{"1", "9"}, actual[1].FromLinkIDs)
+	}
+}
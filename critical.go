@@ -0,0 +1,122 @@
+package depgraph
+
+import (
+	"fmt"
+	"time"
+)
+
+// Schedule is when a node can run within the whole graph, times are from the start of the graph
+type Schedule[K comparable] struct {
+	Node           K
+	Duration       time.Duration
+	EarliestStart  time.Duration
+	EarliestFinish time.Duration
+	LatestStart    time.Duration
+	LatestFinish   time.Duration
+	// Slack is how long the node can be delayed without delaying the end of the graph
+	Slack time.Duration
+	// Critical nodes have no slack
+	Critical bool
+}
+
+// CriticalPath is the result of Graph.CriticalPath
+type CriticalPath[K comparable] struct {
+	// Schedules of every node, in add order
+	Schedules []*Schedule[K]
+	// Path is the longest route through the graph, from a root to a leaf
+	Path []K
+	// LinkIDs are the first link between each pair of nodes on the Path, where there is one
+	LinkIDs []string
+	// Length is the time to the end of the graph, the EarliestFinish of the last node on the Path
+	Length time.Duration
+}
+
+// CriticalPath works out the earliest and latest start of every node, and the critical path, using the
+// AttrDuration of each node and the AttrDelay of the links between them.  These are a time.Duration, or the
+// nanoseconds or duration string a loaded graph has, anything else or a negative value is an error.  Missing
+// durations and delays are 0, where there are several links between two nodes the longest delay is used.
+// A graph with cycles returns a *CycleError
+func (g *Graph[K]) CriticalPath() (*CriticalPath[K], error) {
+	sorted, err := g.SortedStrict()
+	if err != nil {
+		return nil, err
+	}
+	schedules := make(map[K]*Schedule[K], len(sorted))
+	for _, n := range sorted {
+		s := &Schedule[K]{Node: n}
+		if s.Duration, err = durationAttr(g.nodes[n].attrs[AttrDuration]); err == nil && s.Duration < 0 {
+			err = fmt.Errorf("%v is negative", s.Duration)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("node %v %s: %w", n, AttrDuration, err)
+		}
+		for parent := range g.dependencyMap[n] {
+			delay, err := g.linkDelay(parent, n)
+			if err != nil {
+				return nil, err
+			}
+			s.EarliestStart = max(s.EarliestStart, schedules[parent].EarliestFinish+delay)
+		}
+		s.EarliestFinish = s.EarliestStart + s.Duration
+		schedules[n] = s
+	}
+	cp := &CriticalPath[K]{}
+	for _, s := range schedules {
+		cp.Length = max(cp.Length, s.EarliestFinish)
+	}
+	for i := len(sorted) - 1; i >= 0; i-- {
+		s := schedules[sorted[i]]
+		s.LatestFinish = cp.Length
+		for child := range g.dependentMap[s.Node] {
+			delay, _ := g.linkDelay(s.Node, child) // Checked working out the earliest start
+			s.LatestFinish = min(s.LatestFinish, schedules[child].LatestStart-delay)
+		}
+		s.LatestStart = s.LatestFinish - s.Duration
+		s.Slack = s.LatestStart - s.EarliestStart
+		s.Critical = s.Slack == 0
+	}
+	for _, n := range g.nodesInAddOrder() {
+		cp.Schedules = append(cp.Schedules, schedules[n])
+	}
+
+	// Walk back from the first node to finish last through the parents that hold it up
+	var last *Schedule[K]
+	for _, s := range cp.Schedules {
+		if s.Critical && s.EarliestFinish == cp.Length && len(g.dependentMap[s.Node]) == 0 {
+			last = s
+			break
+		}
+	}
+	for s := last; s != nil; {
+		cp.Path = append([]K{s.Node}, cp.Path...)
+		var next *Schedule[K]
+		for _, parent := range g.inAddOrder(g.dependencyMap[s.Node]) {
+			ps := schedules[parent]
+			delay, _ := g.linkDelay(parent, s.Node)
+			if ps.Critical && ps.EarliestFinish+delay == s.EarliestStart {
+				if ids := g.linkIDs(parent, s.Node); len(ids) > 0 && ids[0] != "" {
+					cp.LinkIDs = append([]string{ids[0]}, cp.LinkIDs...)
+				}
+				next = ps
+				break
+			}
+		}
+		s = next
+	}
+	return cp, nil
+}
+
+// linkDelay is the longest AttrDelay of the links from -> to
+func (g *Graph[K]) linkDelay(from, to K) (delay time.Duration, err error) {
+	for _, l := range g.linkMap[from][to] {
+		d, err := durationAttr(l.attrs[AttrDelay])
+		if err == nil && d < 0 {
+			err = fmt.Errorf("%v is negative", d)
+		}
+		if err != nil {
+			return 0, fmt.Errorf("link %v from %v to %v %s: %w", l.id, from, to, AttrDelay, err)
+		}
+		delay = max(delay, d)
+	}
+	return delay, nil
+}
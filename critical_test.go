@@ -0,0 +1,90 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestCriticalPath(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.AddLink("ab", "a", "b"))
+	require.NoError(t, g.AddLink("ac", "a", "c"))
+	require.NoError(t, g.AddLink("bd", "b", "d"))
+	require.NoError(t, g.AddLink("cd", "c", "d"))
+	for n, d := range map[string]time.Duration{"a": 2, "b": 5, "c": 1, "d": 1} {
+		require.NoError(t, g.SetNodeAttr(n, depgraph.AttrDuration, d*time.Hour))
+	}
+	require.NoError(t, g.SetLinkAttrByID("cd", depgraph.AttrDelay, 10*time.Hour))
+
+	cp, err := g.CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "c", "d"}, cp.Path)
+	assert.Equal(t, []string{"ac", "cd"}, cp.LinkIDs)
+	assert.Equal(t, 14*time.Hour, cp.Length)
+
+	require.Len(t, cp.Schedules, 4)
+	b := cp.Schedules[1]
+	assert.Equal(t, depgraph.Schedule[string]{
+		Node:           "b",
+		Duration:       5 * time.Hour,
+		EarliestStart:  2 * time.Hour,
+		EarliestFinish: 7 * time.Hour,
+		LatestStart:    8 * time.Hour,
+		LatestFinish:   13 * time.Hour,
+		Slack:          6 * time.Hour,
+	}, *b)
+	d := cp.Schedules[3]
+	assert.Equal(t, 13*time.Hour, d.EarliestStart)
+	assert.True(t, d.Critical)
+
+	// Without the delay b is on the critical path
+	require.NoError(t, g.SetLinkAttrByID("cd", depgraph.AttrDelay, time.Duration(0)))
+	cp, err = g.CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "d"}, cp.Path)
+	assert.Equal(t, 8*time.Hour, cp.Length)
+
+	require.NoError(t, g.DependOn("a", "d"))
+	_, err = g.CriticalPath()
+	var cycleErr *depgraph.CycleError[string]
+	assert.ErrorAs(t, err, &cycleErr)
+}
+
+func TestCriticalPathLoadedDurations(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.AddLink("ab", "a", "b"))
+	require.NoError(t, g.SetNodeAttr("a", depgraph.AttrDuration, 5*time.Minute))
+	require.NoError(t, g.SetNodeAttr("b", depgraph.AttrDuration, "1m30s"))
+	require.NoError(t, g.SetLinkAttrByID("ab", depgraph.AttrDelay, float64(time.Minute)))
+	cp, err := g.CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, 7*time.Minute+30*time.Second, cp.Length)
+
+	// GraphML loads durations back as nanoseconds
+	var b strings.Builder
+	require.NoError(t, g.WriteGraphML(&b))
+	loaded, err := depgraph.ReadGraphML(strings.NewReader(b.String()))
+	require.NoError(t, err)
+	loadedCP, err := loaded.CriticalPath()
+	require.NoError(t, err)
+	assert.Equal(t, cp.Length, loadedCP.Length)
+
+	require.NoError(t, g.SetNodeAttr("b", depgraph.AttrDuration, "soon"))
+	_, err = g.CriticalPath()
+	assert.ErrorContains(t, err, "node b duration")
+	require.NoError(t, g.SetNodeAttr("b", depgraph.AttrDuration, -time.Minute))
+	_, err = g.CriticalPath()
+	assert.ErrorContains(t, err, "node b duration: -1m0s is negative")
+	require.NoError(t, g.SetNodeAttr("b", depgraph.AttrDuration, time.Minute))
+	require.NoError(t, g.SetLinkAttrByID("ab", depgraph.AttrDelay, true))
+	_, err = g.CriticalPath()
+	assert.ErrorContains(t, err, "link ab from a to b delay")
+	require.NoError(t, g.SetLinkAttrByID("ab", depgraph.AttrDelay, "-1s"))
+	_, err = g.CriticalPath()
+	assert.ErrorContains(t, err, "link ab from a to b delay: -1s is negative")
+}
@@ -0,0 +1,155 @@
+package depgraph
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Cycle is a set of nodes that depend on each other, LinkIDs are the links found between them
+type Cycle[K comparable] struct {
+	Nodes   []K
+	LinkIDs []string
+}
+
+// CycleError is returned by the strict sorts when nodes could not be sorted because of cycles
+type CycleError[K comparable] struct {
+	Cycles []Cycle[K]
+	// Unsorted are all the nodes missing from the sort, the nodes in the cycles and those depending on them
+	Unsorted []K
+}
+
+func (e *CycleError[K]) Error() string {
+	cycles := make([][]K, len(e.Cycles))
+	for i, c := range e.Cycles {
+		cycles[i] = c.Nodes
+	}
+	return fmt.Sprintf("graph has %d cycle(s) %v, %d node(s) could not be sorted", len(e.Cycles), cycles, len(e.Unsorted))
+}
+
+// Cycles returns the nodes of every strongly connected component with more than one node,
+// that is every group of nodes that can reach each other.  The nodes of each cycle, and the cycles by their
+// first node, are ordered by the OrderOptions
+func (g *Graph[K]) Cycles() (cycles [][]K) {
+	for _, component := range g.stronglyConnected() {
+		if len(component) > 1 {
+			cycles = append(cycles, g.sortNodes(component))
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool {
+		return g.compareNodes(cycles[i][0], cycles[j][0]) < 0
+	})
+	return cycles
+}
+
+// SortedLayersStrict is SortedLayers but returns a *CycleError if any nodes are left unsorted
+func (g *Graph[K]) SortedLayersStrict() (layers [][]K, err error) {
+	layers = g.SortedLayers()
+	sorted := make(map[K]bool, len(g.nodes))
+	for _, layer := range layers {
+		for _, n := range layer {
+			sorted[n] = true
+		}
+	}
+	if len(sorted) == len(g.nodes) {
+		return layers, nil
+	}
+	return layers, g.cycleError(sorted)
+}
+
+// SortedStrict is Sorted but returns a *CycleError if any nodes are left unsorted
+func (g *Graph[K]) SortedStrict() (sorted []K, err error) {
+	layers, err := g.SortedLayersStrict()
+	for _, layer := range layers {
+		sorted = append(sorted, layer...)
+	}
+	return sorted, err
+}
+
+func (g *Graph[K]) cycleError(sorted map[K]bool) *CycleError[K] {
+	e := &CycleError[K]{}
+	for _, n := range g.nodesInAddOrder() {
+		if !sorted[n] {
+			e.Unsorted = append(e.Unsorted, n)
+		}
+	}
+	for _, nodes := range g.Cycles() {
+		c := Cycle[K]{Nodes: nodes}
+		for _, from := range nodes {
+			for _, to := range nodes {
+				c.LinkIDs = append(c.LinkIDs, g.linkIDs(from, to)...)
+			}
+		}
+		e.Cycles = append(e.Cycles, c)
+	}
+	return e
+}
+
+// nodesInAddOrder returns the nodes sorted by the order they were added to the graph
+func (g *Graph[K]) nodesInAddOrder() []K {
+	return g.inAddOrder(g.nodes)
+}
+
+// inAddOrder returns the keys of a nodeMap sorted by the order they were added to the graph
+func (g *Graph[K]) inAddOrder(nm nodeMap[K]) []K {
+	nodes := make([]K, 0, len(nm))
+	for n := range nm {
+		nodes = append(nodes, n)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return g.nodes[nodes[i]].addOrder < g.nodes[nodes[j]].addOrder
+	})
+	return nodes
+}
+
+// stronglyConnected uses Tarjan's algorithm to find the strongly connected components, following
+// parent -> child.  Components and their nodes are in add order so the result is repeatable
+func (g *Graph[K]) stronglyConnected() (components [][]K) {
+	type state struct {
+		index, lowLink int
+		onStack        bool
+	}
+	nodes := g.nodesInAddOrder()
+	states := make(map[K]*state, len(nodes))
+	var stack []K
+	var connect func(n K)
+	connect = func(n K) {
+		s := &state{index: len(states), lowLink: len(states), onStack: true}
+		states[n] = s
+		stack = append(stack, n)
+		for child := range g.dependentMap[n] {
+			if cs, visited := states[child]; !visited {
+				connect(child)
+				s.lowLink = min(s.lowLink, states[child].lowLink)
+			} else if cs.onStack {
+				s.lowLink = min(s.lowLink, cs.index)
+			}
+		}
+		if s.lowLink != s.index {
+			return
+		}
+		// n is the root of a component, pop it off the stack
+		var component []K
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			states[top].onStack = false
+			component = append(component, top)
+			if top == n {
+				break
+			}
+		}
+		sort.SliceStable(component, func(i, j int) bool {
+			return g.nodes[component[i]].addOrder < g.nodes[component[j]].addOrder
+		})
+		components = append(components, component)
+	}
+	for _, n := range nodes {
+		if _, visited := states[n]; !visited {
+			connect(n)
+		}
+	}
+	sort.SliceStable(components, func(i, j int) bool {
+		return g.nodes[components[i][0]].addOrder < g.nodes[components[j][0]].addOrder
+	})
+	return components
+}
@@ -0,0 +1,49 @@
+package depgraph_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestCycles(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.AddLink("1", "start", "refresh"))
+	assert.NoError(t, g.AddLink("2", "refresh", "snapshot"))
+	assert.NoError(t, g.AddLink("3", "snapshot", "refresh"))
+	assert.NoError(t, g.AddLink("4", "snapshot", "end"))
+	assert.NoError(t, g.AddLink("5", "start", "other"))
+	assert.NoError(t, g.AddLink("6", "a", "b"))
+	assert.NoError(t, g.AddLink("7", "b", "c"))
+	assert.NoError(t, g.AddLink("8", "c", "a"))
+
+	assert.Equal(t, [][]any{{"refresh", "snapshot"}, {"a", "b", "c"}}, g.Cycles())
+
+	layers, err := g.SortedLayersStrict()
+	assert.Equal(t, [][]any{{"start"}, {"other"}}, layers)
+	var cycleErr *depgraph.CycleError[any]
+	assert.True(t, errors.As(err, &cycleErr))
+	assert.Len(t, cycleErr.Cycles, 2)
+	assert.Equal(t, []any{"refresh", "snapshot"}, cycleErr.Cycles[0].Nodes)
+	assert.ElementsMatch(t, []string{"2", "3"}, cycleErr.Cycles[0].LinkIDs)
+	assert.ElementsMatch(t, []string{"6", "7", "8"}, cycleErr.Cycles[1].LinkIDs)
+	assert.Equal(t, []any{"refresh", "snapshot", "end", "a", "b", "c"}, cycleErr.Unsorted)
+
+	sorted, err := g.SortedStrict()
+	assert.Equal(t, []any{"start", "other"}, sorted)
+	assert.Error(t, err)
+}
+
+func TestNoCycles(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.DependOn("web", "database"))
+	assert.NoError(t, g.DependOn("aggregator", "database"))
+	assert.NoError(t, g.DependOn("web", "aggregator"))
+
+	assert.Empty(t, g.Cycles())
+	sorted, err := g.SortedStrict()
+	assert.NoError(t, err)
+	assert.Equal(t, []any{"database", "aggregator", "web"}, sorted)
+}
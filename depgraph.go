@@ -1,8 +1,10 @@
 package depgraph
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
+	"slices"
 	"sort"
 )
 
@@ -10,93 +12,217 @@ import (
 // https://github.com/kendru/darwin/blob/main/go/depgraph/depgraph.go
 // TimDadd - modified to use any instead of string and new sort algorithm
 
-type node struct {
-	id       any
+type node[K comparable] struct {
+	id       K
 	x        float32
 	y        float32
 	addOrder int
+	attrs    Attributes
 }
 
-// A node in this graph is just any, so a nodeMap is a map whose
+// link is the edge between two nodes, id is the linkID given to AddLink
+type link struct {
+	id    string
+	attrs Attributes
+}
+
+// A node in this graph is identified by its key K, so a nodeMap is a map whose
 // keys are the nodes that are present.  Int can be a weighting if everything else is equal
-type nodeMap map[any]*node
+type nodeMap[K comparable] map[K]*node[K]
 
 // dependencyMap tracks the nodes that have some dependency relationship to
 // some other node, represented by the key of the map.
-type dependencyMap map[any]nodeMap
+type dependencyMap[K comparable] map[K]nodeMap[K]
 
-type TopologyOrder struct {
-	Node       any
+type TopologyOrder[K comparable] struct {
+	Node K
+	// From is the node this step was reached from, nil for the first step of a root branch
+	From       *K
 	FromLinkID string
-	Step       string
-	SortedStep string
+	// FromLinkIDs are all the links from the previous node, FromLinkID is the first of them
+	FromLinkIDs []string
+	Step        string
+	SortedStep  string
+	// BranchStep is the Step of the branch the step is on, the Step without its own number, e.g. 7.2 for
+	// 7.2.1 or A for A.1, empty on the main route
+	BranchStep string
 	Level      int
+	// Join is the first node, in add order, this step leads to that was already sorted, where a branch
+	// joins back into a route.  JoinLinkID is the first link to it
+	Join       *K
+	JoinLinkID string
+	// Attributes of the node and LinkAttributes of the link it was reached by, both are copies
+	Attributes     Attributes
+	LinkAttributes Attributes
 }
 
-type Graph struct {
-	nodes nodeMap
+// Graph is a dependency graph of nodes identified by K, use Graph[any] (see New) to mix key types
+// A Graph is not safe for concurrent use, see SyncGraph
+type Graph[K comparable] struct {
+	nodes nodeMap[K]
 	// Maintain dependency relationships in both directions. These
 	// data structures are the edges of the graph.
 
 	// `dependencyMap` tracks child -> parents.
-	dependencyMap dependencyMap
+	dependencyMap dependencyMap[K]
 	// `dependentMap` tracks parent -> children.
-	dependentMap dependencyMap
-	// Keep track of the nodes of the graph themselves.
-	linkMap map[any]map[any]string
+	dependentMap dependencyMap[K]
+	// Keep track of the links between nodes, from -> to -> links in the order added
+	linkMap map[K]map[K][]*link
+	// linkIndex finds the ends of a link from its ID
+	linkIndex map[string]linkEnds[K]
+	// addCount is the number of nodes ever added, so addOrder stays unique when nodes are removed
+	addCount int
+	// reach is the optional index built by BuildReachabilityIndex
+	reach *reachIndex[K]
+	// order is how ties are broken when ordering nodes
+	order OrderOptions[K]
+
+	orderedTopology []*TopologyOrder[K]
+	handled         map[K]*TopologyOrder[K]
+	unsorted        *Graph[K] // The graph being sorted, before anything was removed
+	strategy        BranchStrategy[K]
+	steps           StepOptions
+}
 
-	orderedTopology []*TopologyOrder
-	handled         map[any]*TopologyOrder
+// New returns a graph where nodes can be any comparable value, so "1" and 1 are different nodes
+func New() *Graph[any] {
+	return NewGraph[any]()
 }
 
-func New() *Graph {
-	return &Graph{
-		dependencyMap: make(dependencyMap, 20),
-		dependentMap:  make(dependencyMap, 20),
-		nodes:         make(nodeMap, 20),
-		linkMap:       make(map[any]map[any]string, 20),
+// NewGraph returns a graph where every node is a K
+func NewGraph[K comparable]() *Graph[K] {
+	return &Graph[K]{
+		dependencyMap: make(dependencyMap[K], 20),
+		dependentMap:  make(dependencyMap[K], 20),
+		nodes:         make(nodeMap[K], 20),
+		linkMap:       make(map[K]map[K][]*link, 20),
+		linkIndex:     make(map[string]linkEnds[K], 20),
 	}
 }
 
-func (g *Graph) Nodes() (nodes []any) {
-	nodes = make([]any, len(g.nodes))
-	i := 0
+// linkEnds are the nodes a link joins
+type linkEnds[K comparable] struct {
+	from, to K
+}
+
+// Nodes returns all the nodes, ordered by the OrderOptions
+func (g *Graph[K]) Nodes() (nodes []K) {
+	nodes = make([]K, 0, len(g.nodes))
 	for n := range g.nodes {
-		nodes[i] = n
-		i++
+		nodes = append(nodes, n)
 	}
-	return nodes
+	return g.sortNodes(nodes)
 }
 
-func (g *Graph) AddNode(id any, x, y float32) {
-	g.nodes[id] = &node{
+// AddNode adds a node at x,y, if the node already exists it is moved and keeps its attributes and add order
+func (g *Graph[K]) AddNode(id K, x, y float32) {
+	if n, ok := g.nodes[id]; ok {
+		n.x, n.y = x, y
+		return
+	}
+	g.nodes[id] = &node[K]{
 		id:       id,
 		x:        x,
 		y:        y,
-		addOrder: len(g.nodes),
+		addOrder: g.nextAddOrder(),
 	}
-	return
 }
 
-// AddLink adds a link between two nodes and records the linkID, only one linkID allowed between nodes
-func (g *Graph) AddLink(linkID string, from, to any) (err error) {
+func (g *Graph[K]) nextAddOrder() (addOrder int) {
+	addOrder = g.addCount
+	g.addCount++
+	return addOrder
+}
+
+// AddLink adds a link between two nodes and records the linkID.  Several links can join the same
+// nodes, e.g. two sequence flows from a gateway, adding a linkID that's already there does nothing.
+// A linkID already used between two other nodes is an error
+func (g *Graph[K]) AddLink(linkID string, from, to K) (err error) {
+	if err = g.checkLinkID(linkID, from, to); err != nil {
+		return
+	}
 	if err = g.DependOn(to, from); err != nil || linkID == "" {
 		return
 	}
-	if linkFromMap, inFromMap := g.linkMap[from]; !inFromMap {
-		g.linkMap[from] = map[any]string{to: linkID}
-	} else if id, inToMap := linkFromMap[to]; !inToMap {
-		linkFromMap[to] = linkID
-	} else {
-		if linkID != id {
-			return fmt.Errorf("link %v and %v both link node %v and %v", linkID, id, from, to)
+	_, err = g.addLink(from, to, linkID)
+	return
+}
+
+// checkLinkID returns an error if linkID is used by a link that isn't from -> to
+func (g *Graph[K]) checkLinkID(linkID string, from, to K) error {
+	if ends, ok := g.linkIndex[linkID]; ok && linkID != "" && ends != (linkEnds[K]{from, to}) {
+		return fmt.Errorf("link %v already joins %v to %v", linkID, ends.from, ends.to)
+	}
+	return nil
+}
+
+// addLink returns the link with linkID between two nodes, creating it if needed.  A link without
+// an ID (from SetLinkAttr) is given the linkID rather than adding another link
+func (g *Graph[K]) addLink(from, to K, linkID string) (*link, error) {
+	if err := g.checkLinkID(linkID, from, to); err != nil {
+		return nil, err
+	}
+	if linkID != "" {
+		if g.linkIndex == nil {
+			g.linkIndex = make(map[string]linkEnds[K])
 		}
+		g.linkIndex[linkID] = linkEnds[K]{from, to}
+	}
+	linkFromMap, inFromMap := g.linkMap[from]
+	if !inFromMap {
+		linkFromMap = make(map[K][]*link)
+		g.linkMap[from] = linkFromMap
+	}
+	for _, l := range linkFromMap[to] {
+		if l.id == linkID {
+			return l, nil
+		}
+	}
+	for _, l := range linkFromMap[to] {
+		if l.id == "" {
+			l.id = linkID
+			return l, nil
+		}
+	}
+	l := &link{id: linkID}
+	linkFromMap[to] = append(linkFromMap[to], l)
+	return l, nil
+}
+
+// linkIDs returns the IDs of all the links between two nodes in the order they were added
+func (g *Graph[K]) linkIDs(from, to K) (ids []string) {
+	for _, l := range g.linkMap[from][to] {
+		if l.id != "" {
+			ids = append(ids, l.id)
+		}
+	}
+	return ids
+}
+
+// findLink returns the ends of the link with linkID
+func (g *Graph[K]) findLink(linkID string) (from, to K, l *link, found bool) {
+	ends, ok := g.linkIndex[linkID]
+	if !ok || linkID == "" {
+		return from, to, nil, false
+	}
+	for _, l := range g.linkMap[ends.from][ends.to] {
+		if l.id == linkID {
+			return ends.from, ends.to, l, true
+		}
+	}
+	return from, to, nil, false
+}
+
+// unindexLinks removes the IDs of the links from -> to from the linkIndex
+func (g *Graph[K]) unindexLinks(from, to K) {
+	for _, l := range g.linkMap[from][to] {
+		delete(g.linkIndex, l.id)
 	}
-	return
 }
 
 // DependOn sets a dependency between a child and parent
-func (g *Graph) DependOn(child, parent any) error {
+func (g *Graph[K]) DependOn(child, parent K) error {
 	if child == parent {
 		return errors.New("self-referential dependencyMap not allowed")
 	}
@@ -107,61 +233,72 @@ func (g *Graph) DependOn(child, parent any) error {
 
 	// Add nodes if not already added
 	if n := g.nodes[parent]; n == nil {
-		g.nodes[parent] = &node{
+		g.nodes[parent] = &node[K]{
 			id:       parent,
 			x:        0,
 			y:        0,
-			addOrder: len(g.nodes),
+			addOrder: g.nextAddOrder(),
 		}
 	}
 	if n := g.nodes[child]; n == nil {
-		g.nodes[child] = &node{
+		g.nodes[child] = &node[K]{
 			id:       child,
 			x:        0,
 			y:        0,
-			addOrder: len(g.nodes),
+			addOrder: g.nextAddOrder(),
 		}
 	}
 
 	// Add edges.
 	addNodeToNodeset(g.dependentMap, parent, child)
 	addNodeToNodeset(g.dependencyMap, child, parent)
+	if g.reach != nil {
+		g.reach.dependOn(child, parent)
+	}
 
 	return nil
 }
 
 // DependsOn returns true if child depends on parent
-func (g *Graph) DependsOn(child, parent any) bool {
+func (g *Graph[K]) DependsOn(child, parent K) bool {
+	if g.reach != nil {
+		return g.reach.reaches(parent, child)
+	}
 	deps := g.dependencies(child)
 	_, ok := deps[parent]
 	return ok
 }
 
 // HasDependent returns true if child is dependent on parent
-func (g *Graph) HasDependent(parent, child any) bool {
+func (g *Graph[K]) HasDependent(parent, child K) bool {
+	if g.reach != nil {
+		return g.reach.reaches(parent, child)
+	}
 	deps := g.dependents(parent)
 	_, ok := deps[child]
 	return ok
 }
 
-// Leaves finds all nodes that don't have a dependency
-func (g *Graph) Leaves() (leaves []any) {
+// Leaves finds all nodes that don't have a dependency, ordered by the OrderOptions
+func (g *Graph[K]) Leaves() (leaves []K) {
 	for nodeID := range g.nodes {
 		if _, ok := g.dependencyMap[nodeID]; !ok {
 			leaves = append(leaves, nodeID)
 		}
 	}
-	return leaves
+	return g.sortNodes(leaves)
 }
 
 // SortedLayers returns a slice of graph nodes in topological sort order. That is,
 // if `B` depends on `A`, then `A` is guaranteed to come before `B` in the sorted output.
-// The graph is guaranteed to be cycle-free because cycles are detected while building the
-// graph. Additionally, the output is grouped into "layers", which are guaranteed to not have
+// Nodes on a cycle, and every node depending on one, can't be sorted and are left out, use
+// `Graph.SortedLayersStrict()` to get an error naming them or `Graph.Cycles()` to find the cycles.
+// Additionally, the output is grouped into "layers", which are guaranteed to not have
 // any dependencyMap within each layer. This is useful, e.g. when building an execution plan for
 // some DAG, in which case each element within each layer could be executed in parallel. If you
-// do not need this layered property, use `Graph.TopoSorted()`, which flattens all elements.
-func (g *Graph) SortedLayers() (layers [][]any) {
+// do not need this layered property, use `Graph.Sorted()`, which flattens all elements.
+// Nodes in a layer are sorted by number of dependents and then by the OrderOptions.
+func (g *Graph[K]) SortedLayers() (layers [][]K) {
 	// Copy the graph
 	shrinkingGraph := g.clone()
 	for {
@@ -171,12 +308,13 @@ func (g *Graph) SortedLayers() (layers [][]any) {
 		}
 		if len(leaves) > 1 {
 			// Sort the leaves by number of dependentMap
-			dependents := make(map[any]int, len(leaves))
+			dependents := make(map[K]int, len(leaves))
 			for _, leafNode := range leaves {
 				dependents[leafNode] = len(g.dependents(leafNode))
 			}
-			sort.Slice(leaves, func(i, j int) bool {
-				return dependents[leaves[i]] < dependents[leaves[j]]
+			// Stable so leaves with the same number keep the OrderOptions order from Leaves
+			slices.SortStableFunc(leaves, func(a, b K) int {
+				return cmp.Compare(dependents[a], dependents[b])
 			})
 		}
 
@@ -189,7 +327,7 @@ func (g *Graph) SortedLayers() (layers [][]any) {
 	return layers
 }
 
-func removeFromDepMap(dm dependencyMap, key, nodeId any) {
+func removeFromDepMap[K comparable](dm dependencyMap[K], key, nodeId K) {
 	nMap := dm[key]
 	if len(nMap) == 1 {
 		// The only element in the nodeMap must be `node`, so we
@@ -201,7 +339,7 @@ func removeFromDepMap(dm dependencyMap, key, nodeId any) {
 	}
 }
 
-func (g *Graph) remove(nodeID any) {
+func (g *Graph[K]) remove(nodeID K) {
 	// Remove edges from things that depend on `node`.
 	for dependent := range g.dependentMap[nodeID] {
 		removeFromDepMap(g.dependencyMap, dependent, nodeID)
@@ -218,43 +356,100 @@ func (g *Graph) remove(nodeID any) {
 	delete(g.nodes, nodeID)
 }
 
-func (g *Graph) dependencies(child any) nodeMap {
+// RemoveNode removes a node along with all the dependencies and links to and from it
+func (g *Graph[K]) RemoveNode(nodeID K) error {
+	if _, ok := g.nodes[nodeID]; !ok {
+		return fmt.Errorf("node %v not found", nodeID)
+	}
+	g.remove(nodeID)
+	g.reach = nil
+	for to := range g.linkMap[nodeID] {
+		g.unindexLinks(nodeID, to)
+	}
+	delete(g.linkMap, nodeID)
+	for from, toLinkMap := range g.linkMap {
+		g.unindexLinks(from, nodeID)
+		delete(toLinkMap, nodeID)
+		if len(toLinkMap) == 0 {
+			delete(g.linkMap, from)
+		}
+	}
+	return nil
+}
+
+// RemoveLink removes the dependency between two nodes and all the links recorded for it, the nodes remain
+func (g *Graph[K]) RemoveLink(from, to K) error {
+	if _, ok := g.dependentMap[from][to]; !ok {
+		return fmt.Errorf("no link from %v to %v", from, to)
+	}
+	removeFromDepMap(g.dependentMap, from, to)
+	removeFromDepMap(g.dependencyMap, to, from)
+	g.reach = nil
+	g.unindexLinks(from, to)
+	if toLinkMap, inMap := g.linkMap[from]; inMap {
+		delete(toLinkMap, to)
+		if len(toLinkMap) == 0 {
+			delete(g.linkMap, from)
+		}
+	}
+	return nil
+}
+
+// RemoveLinkByID removes the link recorded with linkID, the dependency between the nodes is
+// only removed when it was the last link between them
+func (g *Graph[K]) RemoveLinkByID(linkID string) error {
+	from, to, l, found := g.findLink(linkID)
+	if !found {
+		return fmt.Errorf("link %v not found", linkID)
+	}
+	links := g.linkMap[from][to]
+	if len(links) == 1 {
+		return g.RemoveLink(from, to)
+	}
+	g.linkMap[from][to] = slices.DeleteFunc(links, func(other *link) bool { return other == l })
+	delete(g.linkIndex, linkID)
+	return nil
+}
+
+func (g *Graph[K]) dependencies(child K) nodeMap[K] {
 	return g.buildTransitive(child, g.immediateDependencies)
 }
 
-func (g *Graph) immediateDependencies(node any) nodeMap {
+func (g *Graph[K]) immediateDependencies(node K) nodeMap[K] {
 	return g.dependencyMap[node]
 }
 
-func (g *Graph) dependents(parent any) nodeMap {
+func (g *Graph[K]) dependents(parent K) nodeMap[K] {
 	return g.buildTransitive(parent, g.immediateDependents)
 }
 
-func (g *Graph) immediateDependents(node any) nodeMap {
+func (g *Graph[K]) immediateDependents(node K) nodeMap[K] {
 	return g.dependentMap[node]
 }
 
-func (g *Graph) clone() *Graph {
-	return &Graph{
+func (g *Graph[K]) clone() *Graph[K] {
+	return &Graph[K]{
 		dependencyMap: copyDepMap(g.dependencyMap),
 		dependentMap:  copyDepMap(g.dependentMap),
 		nodes:         copyNodeset(g.nodes),
 		linkMap:       g.linkMap, // This can be a pointer as it doesn't get mangled
+		linkIndex:     g.linkIndex,
+		order:         g.order,
 	}
 }
 
 // buildTransitive starts at `root` and continues calling `nextFn` to keep discovering more nodes until
 // the graph is exhausted. It returns the set of all discovered nodes.
-func (g *Graph) buildTransitive(rootNodeId any, nextFn func(any) nodeMap) nodeMap {
+func (g *Graph[K]) buildTransitive(rootNodeId K, nextFn func(K) nodeMap[K]) nodeMap[K] {
 	if _, ok := g.nodes[rootNodeId]; !ok {
 		return nil
 	}
-	out := make(nodeMap)
-	searchNext := []any{rootNodeId}
+	out := make(nodeMap[K])
+	searchNext := []K{rootNodeId}
 	for len(searchNext) > 0 {
 		// List of new nodes from this layer of the dependency graph. This is
 		// assigned to `searchNext` at the end of the outer "discovery" loop.
-		var discovered []any
+		var discovered []K
 		for _, nextNodeId := range searchNext {
 			// For each node to discover, find the next nodes.
 			for nextNode := range nextFn(nextNodeId) {
@@ -272,31 +467,31 @@ func (g *Graph) buildTransitive(rootNodeId any, nextFn func(any) nodeMap) nodeMa
 	return out
 }
 
-func copyNodeset(s nodeMap) nodeMap {
-	out := make(nodeMap, len(s))
+func copyNodeset[K comparable](s nodeMap[K]) nodeMap[K] {
+	out := make(nodeMap[K], len(s))
 	for k, v := range s {
 		out[k] = v
 	}
 	return out
 }
 
-func copyDepMap(m dependencyMap) dependencyMap {
-	out := make(dependencyMap, len(m))
+func copyDepMap[K comparable](m dependencyMap[K]) dependencyMap[K] {
+	out := make(dependencyMap[K], len(m))
 	for k, v := range m {
 		out[k] = copyNodeset(v)
 	}
 	return out
 }
 
-func addNodeToNodeset(dm dependencyMap, key, nodeId any) {
-	n := &node{
+func addNodeToNodeset[K comparable](dm dependencyMap[K], key, nodeId K) {
+	n := &node[K]{
 		id: nodeId,
 		x:  0,
 		y:  0,
 	}
 	if nodes, ok := dm[key]; !ok {
 		n.addOrder = 0
-		nodes = nodeMap{nodeId: n} // Initialise the map
+		nodes = nodeMap[K]{nodeId: n} // Initialise the map
 		dm[key] = nodes
 	} else {
 		n.addOrder = len(nodes)
@@ -305,14 +500,14 @@ func addNodeToNodeset(dm dependencyMap, key, nodeId any) {
 }
 
 // Sorted returns all the nodes in the graph sorted by layers
-func (g *Graph) Sorted() []any {
+func (g *Graph[K]) Sorted() []K {
 	nodeCount := 0
 	layers := g.SortedLayers()
 	for _, layer := range layers {
 		nodeCount += len(layer)
 	}
 
-	allNodes := make([]any, 0, nodeCount)
+	allNodes := make([]K, 0, nodeCount)
 	for _, layer := range layers {
 		for _, n := range layer {
 			allNodes = append(allNodes, n)
@@ -324,11 +519,29 @@ func (g *Graph) Sorted() []any {
 
 // TopologicalSort tries to prioritise the longest branch and is good for sequence diagrams
 // Any off shoots are handled before carrying on
-func (g *Graph) TopologicalSort() []*TopologyOrder {
+func (g *Graph[K]) TopologicalSort() []*TopologyOrder[K] {
+	return g.TopologicalSortWithOptions(TopologicalSortOptions[K]{})
+}
+
+// TopologicalSortWithOptions is TopologicalSort with the main route chosen by opts.Strategy and the
+// steps numbered using opts.Steps
+func (g *Graph[K]) TopologicalSortWithOptions(opts TopologicalSortOptions[K]) []*TopologyOrder[K] {
 	// Copy the graph, so we can remove things we've visited
 	shrinkingGraph := g.clone()
-	shrinkingGraph.handled = make(map[any]*TopologyOrder, len(g.nodes))
-	shrinkingGraph.sortLeaves("", "", 0, 0, nil, nil)
+	shrinkingGraph.handled = make(map[K]*TopologyOrder[K], len(g.nodes))
+	shrinkingGraph.unsorted = g
+	shrinkingGraph.strategy = opts.Strategy
+	if shrinkingGraph.strategy == nil {
+		shrinkingGraph.strategy = DefaultBranchStrategy[K]{}
+	}
+	shrinkingGraph.steps = opts.Steps
+	if shrinkingGraph.steps.Formatter == nil {
+		shrinkingGraph.steps.Formatter = DefaultStepFormat
+	}
+	if shrinkingGraph.steps.SortedPadding <= 0 {
+		shrinkingGraph.steps.SortedPadding = 4
+	}
+	shrinkingGraph.sortLeaves(nil, "", 0, 0, nil, nil)
 	sort.Slice(shrinkingGraph.orderedTopology, func(i, j int) bool {
 		return shrinkingGraph.orderedTopology[i].SortedStep < shrinkingGraph.orderedTopology[j].SortedStep
 	})
@@ -337,9 +550,9 @@ func (g *Graph) TopologicalSort() []*TopologyOrder {
 
 // sortLeaves is a shrinking graph algorithm, that is, as we deal with something we remove from the graph
 // Stops any issues with recursion in the graph
-func (g *Graph) sortLeaves(prefix, sortedPrefix string, parent, level int, previousNode any, children nodeMap) {
-	rootLeaf := prefix == "" && parent == 0 && level == 0
-	var leaves []any
+func (g *Graph[K]) sortLeaves(prefix []StepPart, sortedPrefix string, parent, level int, previousNode *K, children nodeMap[K]) {
+	rootLeaf := len(prefix) == 0 && parent == 0 && level == 0
+	var leaves []K
 	if children == nil {
 		leaves = g.Leaves() // Find all nodes that don't have a dependency
 	} else {
@@ -353,29 +566,12 @@ func (g *Graph) sortLeaves(prefix, sortedPrefix string, parent, level int, previ
 		return
 	}
 	if len(leaves) > 1 {
-		// Sort the leaves by number of dependentMap, most dependentMap first
-		dependents := make(map[any]int, len(leaves))
-		for _, leafNode := range leaves {
-			dependents[leafNode] = len(g.dependents(leafNode))
-		}
-		sort.Slice(leaves, func(i, j int) bool {
-			// Pick dependents over co-ordinates except when the root - then try and start top left
-			if dependents[leaves[i]] == dependents[leaves[j]] || rootLeaf {
-				nodeI := g.nodes[leaves[i]]
-				nodeJ := g.nodes[leaves[j]]
-				if nodeI.x != nodeJ.x {
-					return nodeI.x < nodeJ.x
-				} else if nodeI.y != nodeJ.y {
-					return nodeI.y < nodeJ.y
-				}
-				return nodeI.addOrder < nodeI.addOrder
-			}
-			return dependents[leaves[i]] > dependents[leaves[j]] // One with the longest path
-		})
+		g.sortBranches(leaves, previousNode, rootLeaf)
 	}
 	offset := parent + 1
 	parentPrefix := prefix
 	parentSortedPrefix := sortedPrefix
+	width := g.steps.SortedPadding
 	fromNode := previousNode
 	for i, leafNode := range leaves {
 		//stopAts := []string{"Event_1gnl54n", "Activity_0l71uiq"} //Activity_00qw565
@@ -397,30 +593,52 @@ func (g *Graph) sortLeaves(prefix, sortedPrefix string, parent, level int, previ
 				level++
 			}
 			if rootLeaf {
-				prefix = fmt.Sprintf("%c.", 64+i) // Use a letter for the top layer - different paths!
-				sortedPrefix = prefix
+				prefix = []StepPart{{Number: i, Root: true}} // A root part for the top layer - different paths!
+				sortedPrefix = fmt.Sprintf("%c.", 64+i)
 			} else { // Prefix format depends on number of leaves
 				fromNode = previousNode
-				switch len(leaves) {
-				case 2: // If we only have two leaves then we simplify the second prefix (i.e. 1-1,1-2,1-3)
-					prefix = fmt.Sprintf("%s%d.", parentPrefix, parent)
-					sortedPrefix = fmt.Sprintf("%s%04d.", parentSortedPrefix, parent)
+				switch {
+				case len(leaves) == 2 && !g.steps.FullBranchNumbers:
+					// If we only have two leaves then we simplify the second prefix (i.e. 1-1,1-2,1-3)
+					prefix = slices.Concat(parentPrefix, []StepPart{{Number: parent}})
+					sortedPrefix = fmt.Sprintf("%s%0*d.", parentSortedPrefix, width, parent)
 				default: // More than 2 leaves then full-fat prefix (i.e. 1-1-1, 1-2-1, 1-3-1 etc.)
-					prefix = fmt.Sprintf("%s%d.%d.", parentPrefix, parent, i)
-					sortedPrefix = fmt.Sprintf("%s%04d.%04d.", parentSortedPrefix, parent, i)
+					prefix = slices.Concat(parentPrefix, []StepPart{{Number: parent}, {Number: i}})
+					sortedPrefix = fmt.Sprintf("%s%0*d.%0*d.", parentSortedPrefix, width, parent, width, i)
 				}
 			}
 		}
-		to := &TopologyOrder{
+		to := &TopologyOrder[K]{
 			Node:       leafNode,
 			FromLinkID: "",
-			Step:       fmt.Sprintf("%s%d", prefix, offset),
-			SortedStep: fmt.Sprintf("%s%04d", sortedPrefix, offset),
+			Step:       g.steps.Formatter.FormatStep(slices.Concat(prefix, []StepPart{{Number: offset}})),
+			SortedStep: fmt.Sprintf("%s%0*d", sortedPrefix, width, offset),
 			Level:      level,
+			Attributes: g.nodes[leafNode].attrs.clone(),
+		}
+		if len(prefix) > 0 {
+			to.BranchStep = g.steps.Formatter.FormatStep(prefix)
+		}
+		if fromNode != nil && !rootLeaf {
+			from := *fromNode
+			to.From = &from
 		}
 		if fromNode != nil && len(g.linkMap) > 0 {
-			if toLinkMap, inMap := g.linkMap[fromNode]; inMap {
-				to.FromLinkID = toLinkMap[leafNode]
+			if links := g.linkMap[*fromNode][leafNode]; len(links) > 0 {
+				to.FromLinkID = links[0].id
+				to.FromLinkIDs = g.linkIDs(*fromNode, leafNode)
+				to.LinkAttributes = links[0].attrs.clone()
+			}
+		}
+		// The edges to nodes already handled have been removed, these are where the route joins another
+		for _, child := range g.unsorted.inAddOrder(g.unsorted.dependentMap[leafNode]) {
+			if _, ok := g.handled[child]; ok {
+				join := child
+				to.Join = &join
+				if ids := g.linkIDs(leafNode, child); len(ids) > 0 {
+					to.JoinLinkID = ids[0]
+				}
+				break
 			}
 		}
 		g.orderedTopology = append(g.orderedTopology, to)
@@ -430,15 +648,45 @@ func (g *Graph) sortLeaves(prefix, sortedPrefix string, parent, level int, previ
 		// If we're following a path then keep following until the end
 		// If this is a singleton root step then don't go down a level
 		if c == nil && children != nil || (rootLeaf && c == nil) {
-			fromNode = leafNode
+			fromNode = &leafNode
 			continue
 		}
-		g.sortLeaves(prefix, sortedPrefix, offset, level, leafNode, c)
+		g.sortLeaves(prefix, sortedPrefix, offset, level, &leafNode, c)
+	}
+}
+
+// sortBranches puts the leaves in the order they are followed, the main route first
+func (g *Graph[K]) sortBranches(leaves []K, from *K, root bool) {
+	branches := make(map[K]*Branch[K], len(leaves))
+	for _, leafNode := range leaves {
+		n := g.nodes[leafNode]
+		b := &Branch[K]{
+			Node:       leafNode,
+			Root:       root,
+			Dependents: len(g.dependents(leafNode)),
+			X:          n.x,
+			Y:          n.y,
+			AddOrder:   n.addOrder,
+			Attributes: n.attrs.clone(),
+		}
+		if from != nil && !root {
+			b.From = from
+			if l := g.firstLink(*from, leafNode); l != nil {
+				b.LinkAttributes = l.attrs.clone()
+			}
+		}
+		branches[leafNode] = b
 	}
+	slices.SortFunc(leaves, func(a, b K) int {
+		if c := g.strategy.Compare(branches[a], branches[b]); c != 0 {
+			return c
+		}
+		return cmp.Compare(branches[a].AddOrder, branches[b].AddOrder)
+	})
 }
 
 // unhandledLeaves finds all nodes that don't have a dependency
-func (g *Graph) unhandledLeaves() (leaves []any) {
+func (g *Graph[K]) unhandledLeaves() (leaves []K) {
 	for node := range g.nodes {
 		if _, ok := g.dependencyMap[node]; !ok {
 			leaves = append(leaves, node)
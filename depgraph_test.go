@@ -1,9 +1,10 @@
 package depgraph_test
 
 import (
-	"depgraph"
-	"github.com/stretchr/testify/assert"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
 )
 
 func TestImmediateDependencies(t *testing.T) {
@@ -48,7 +49,7 @@ func TestLeaves(t *testing.T) {
 	assert.NoError(t, g.DependOn("grain", "soil"))
 
 	leaves := g.Leaves()
-	assert.ElementsMatch(t, leaves, []string{"feed", "soil"})
+	assert.Equal(t, []any{"feed", "soil"}, leaves)
 }
 
 type pair struct {
@@ -210,14 +211,14 @@ func TestLayeredTopologicalSort(t *testing.T) {
 	//	t.Logf("Layer:%d,Nodes:%v", i, l)
 	//}
 	assert.Len(t, layers, 4)
-	assert.ElementsMatch(t, []string{"config", "logger"}, layers[0])
-	assert.ElementsMatch(t, []string{"database", "metrics"}, layers[1])
-	assert.ElementsMatch(t, []string{"aggregator"}, layers[2])
-	assert.ElementsMatch(t, []string{"web"}, layers[3])
+	assert.Equal(t, []any{"logger", "config"}, layers[0])
+	assert.Equal(t, []any{"metrics", "database"}, layers[1])
+	assert.Equal(t, []any{"aggregator"}, layers[2])
+	assert.Equal(t, []any{"web"}, layers[3])
 }
 
 // Already the items have been added to the graph
-func testTopologicalSort(t *testing.T, g *depgraph.Graph, expect []orderNode, useSortedStep, checkLinks bool) {
+func testTopologicalSort(t *testing.T, g *depgraph.Graph[any], expect []orderNode, useSortedStep, checkLinks bool) {
 	assert.Len(t, g.Nodes(), len(expect))
 	actual := g.TopologicalSort()
 	assert.Len(t, actual, len(expect))
@@ -848,3 +849,78 @@ func TestTopologicalSort007(t *testing.T) {
 	}
 	testTopologicalSort(t, g, expect, true, false)
 }
+
+func TestRemove(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.AddLink("1", "a", "b"))
+	assert.NoError(t, g.AddLink("2", "b", "c"))
+	assert.NoError(t, g.AddLink("3", "a", "c"))
+	assert.NoError(t, g.AddLink("4", "c", "d"))
+
+	assert.NoError(t, g.RemoveLink("a", "c"))
+	assert.True(t, g.DependsOn("c", "a")) // Still linked through b
+	assert.Error(t, g.RemoveLink("a", "c"))
+	assert.Len(t, g.Nodes(), 4)
+
+	assert.NoError(t, g.RemoveLinkByID("2"))
+	assert.False(t, g.DependsOn("c", "a"))
+	assert.Error(t, g.RemoveLinkByID("2"))
+	assert.Equal(t, []any{"a", "c"}, g.Leaves())
+
+	assert.NoError(t, g.RemoveNode("c"))
+	assert.Error(t, g.RemoveNode("c"))
+	assert.Equal(t, []any{"a", "b", "d"}, g.Nodes())
+	assert.Equal(t, []any{"a", "d"}, g.Leaves())
+	assert.Error(t, g.RemoveLinkByID("4"))
+
+	// Removing one of several links keeps the dependency
+	assert.NoError(t, g.AddLink("5", "a", "b"))
+	assert.NoError(t, g.RemoveLinkByID("1"))
+	assert.True(t, g.DependsOn("b", "a"))
+	assert.NoError(t, g.AddLink("6", "b", "d"))
+	actual := g.TopologicalSort()
+	assert.Len(t, actual, 3)
+	assert.Equal(t, "5", actual[1].FromLinkID)
+	assert.Equal(t, "6", actual[2].FromLinkID)
+	assert.NoError(t, g.RemoveLinkByID("5"))
+	assert.False(t, g.DependsOn("b", "a"))
+}
+
+func TestGenericGraph(t *testing.T) {
+	g := depgraph.NewGraph[int]()
+	assert.NoError(t, g.AddLink("a", 1, 2))
+	assert.NoError(t, g.AddLink("b", 2, 3))
+	assert.NoError(t, g.DependOn(4, 1))
+
+	var sorted []int = g.Sorted()
+	assert.Equal(t, 1, sorted[0])
+	assert.True(t, g.DependsOn(3, 1))
+	actual := g.TopologicalSort()
+	assert.Len(t, actual, 4)
+	assert.Equal(t, 1, actual[0].Node)
+
+	// Graph[any] treats "1" and 1 as different nodes
+	a := depgraph.New()
+	assert.NoError(t, a.DependOn("1", 1))
+	assert.Len(t, a.Nodes(), 2)
+}
+
+func TestDuplicateLinkID(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("1", "a", "b"))
+	assert.NoError(t, g.AddLink("1", "a", "b")) // Already there
+	assert.Error(t, g.AddLink("1", "c", "d"))
+	assert.Len(t, g.Nodes(), 2)
+
+	assert.NoError(t, g.SetLinkAttrByID("1", depgraph.AttrLabel, "One"))
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrLabel: "One"}, g.LinkAttrs("a", "b"))
+
+	// IDs can be used again once their link has gone
+	assert.NoError(t, g.RemoveLinkByID("1"))
+	assert.NoError(t, g.AddLink("1", "c", "d"))
+	assert.NoError(t, g.RemoveNode("d"))
+	assert.NoError(t, g.AddLink("1", "b", "c"))
+	assert.NoError(t, g.RemoveLink("b", "c"))
+	assert.NoError(t, g.AddLink("1", "a", "c"))
+	assert.Equal(t, []string{"1"}, g.LinkIDs("a", "c"))
+}
@@ -0,0 +1,124 @@
+package depgraph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// DOTOptions controls what WriteDOT includes
+type DOTOptions struct {
+	// Name of the digraph, "depgraph" when empty
+	Name string
+	// Positions pins each node at its x,y.  y is negated as DOT has y going up and diagrams have it going down
+	Positions bool
+	// Layers puts the nodes of each SortedLayers layer in its own cluster
+	Layers bool
+	// Topology colours the nodes by TopologyOrder.Level and adds the Step to the label
+	Topology bool
+}
+
+// WriteDOT writes the graph in Graphviz DOT format.  Nodes are labelled with their AttrLabel attribute,
+// or the node itself when not set, and there is an edge for each link labelled with the linkID
+func (g *Graph[K]) WriteDOT(w io.Writer, opts DOTOptions) error {
+	name := opts.Name
+	if name == "" {
+		name = "depgraph"
+	}
+	nodes := g.nodesInAddOrder()
+	dotIDs := g.diagramIDs()
+	var topology map[K]*TopologyOrder[K]
+	if opts.Topology {
+		topology = make(map[K]*TopologyOrder[K], len(nodes))
+		for _, to := range g.TopologicalSort() {
+			topology[to.Node] = to
+		}
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "digraph %s {\n", dotQuote(name))
+	writeNode := func(indent string, n K) {
+		label := g.label(n)
+		var attrs []string
+		if to, ok := topology[n]; ok {
+			label = to.Step + "\n" + label
+			attrs = append(attrs, "style=filled", "colorscheme=set312", fmt.Sprintf("fillcolor=%d", to.Level%12+1))
+		}
+		attrs = append([]string{"label=" + dotQuote(label)}, attrs...)
+		if opts.Positions {
+			nd := g.nodes[n]
+			attrs = append(attrs, fmt.Sprintf(`pos="%g,%g!"`, nd.x, -nd.y))
+		}
+		fmt.Fprintf(&b, "%s%s [%s];\n", indent, dotIDs[n], strings.Join(attrs, " "))
+	}
+	if opts.Layers {
+		inLayer := make(map[K]bool, len(nodes))
+		for i, layer := range g.SortedLayers() {
+			fmt.Fprintf(&b, "  subgraph cluster_%d {\n    label=\"Layer %d\";\n", i, i)
+			for _, n := range layer {
+				writeNode("    ", n)
+				inLayer[n] = true
+			}
+			b.WriteString("  }\n")
+		}
+		// Nodes on cycles aren't in any layer
+		for _, n := range nodes {
+			if !inLayer[n] {
+				writeNode("  ", n)
+			}
+		}
+	} else {
+		for _, n := range nodes {
+			writeNode("  ", n)
+		}
+	}
+	for _, e := range g.Edges() {
+		if e.LinkID == "" {
+			fmt.Fprintf(&b, "  %s -> %s;\n", dotIDs[e.From], dotIDs[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s -> %s [label=%s];\n", dotIDs[e.From], dotIDs[e.To], dotQuote(e.LinkID))
+		}
+	}
+	b.WriteString("}\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// label returns the AttrLabel of a node, or the node itself if it doesn't have a label
+func (g *Graph[K]) label(n K) string {
+	if nd, inGraph := g.nodes[n]; inGraph {
+		if label, ok := nd.attrs[AttrLabel]; ok {
+			return fmt.Sprint(label)
+		}
+	}
+	return fmt.Sprint(n)
+}
+
+// diagramIDs returns an identifier for each node, safe to use in any diagram language,
+// based on the order the nodes were added
+func (g *Graph[K]) diagramIDs() diagramIDMap[K] {
+	ids := make(diagramIDMap[K], len(g.nodes))
+	for i, n := range g.nodesInAddOrder() {
+		ids[n] = fmt.Sprintf("n%d", i)
+	}
+	return ids
+}
+
+// diagramIDMap is the identifier of each node from diagramIDs
+type diagramIDMap[K comparable] map[K]string
+
+// id returns the identifier of n, a node not in the graph, e.g. removed since the sort, is given a new one
+func (ids diagramIDMap[K]) id(n K) string {
+	nodeID, ok := ids[n]
+	if !ok {
+		nodeID = fmt.Sprintf("x%d", len(ids))
+		ids[n] = nodeID
+	}
+	return nodeID
+}
+
+// dotQuote quotes s as a DOT string, backslashes and quotes are escaped and newlines become DOT line breaks
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
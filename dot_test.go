@@ -0,0 +1,59 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestWriteDOT(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("start", 10, 20)
+	g.AddNode("check", 100, 20)
+	g.AddNode("end", 200, 40)
+	assert.NoError(t, g.AddLink("1", "start", "check"))
+	assert.NoError(t, g.AddLink("2", "check", "end"))
+	assert.NoError(t, g.DependOn("end", "start"))
+	assert.NoError(t, g.SetNodeAttr("check", depgraph.AttrLabel, `Check "Order"`))
+
+	var b strings.Builder
+	assert.NoError(t, g.WriteDOT(&b, depgraph.DOTOptions{Positions: true}))
+	assert.Equal(t, `digraph "depgraph" {
+  n0 [label="start" pos="10,-20!"];
+  n1 [label="Check \"Order\"" pos="100,-20!"];
+  n2 [label="end" pos="200,-40!"];
+  n0 -> n1 [label="1"];
+  n0 -> n2;
+  n1 -> n2 [label="2"];
+}
+`, b.String())
+
+	b.Reset()
+	assert.NoError(t, g.WriteDOT(&b, depgraph.DOTOptions{Name: "order", Layers: true, Topology: true}))
+	assert.Equal(t, `digraph "order" {
+  subgraph cluster_0 {
+    label="Layer 0";
+    n0 [label="1\nstart" style=filled colorscheme=set312 fillcolor=1];
+  }
+  subgraph cluster_1 {
+    label="Layer 1";
+    n1 [label="2\nCheck \"Order\"" style=filled colorscheme=set312 fillcolor=1];
+  }
+  subgraph cluster_2 {
+    label="Layer 2";
+    n2 [label="3\nend" style=filled colorscheme=set312 fillcolor=1];
+  }
+  n0 -> n1 [label="1"];
+  n0 -> n2;
+  n1 -> n2 [label="2"];
+}
+`, b.String())
+
+	// A trailing backslash mustn't escape the closing quote
+	assert.NoError(t, g.SetNodeAttr("end", depgraph.AttrLabel, `C:\`))
+	b.Reset()
+	assert.NoError(t, g.WriteDOT(&b, depgraph.DOTOptions{}))
+	assert.Contains(t, b.String(), `n2 [label="C:\\"];`)
+}
@@ -0,0 +1,37 @@
+package depgraph
+
+// Edge is a link between two nodes, a dependency without a linkID is an Edge with an empty LinkID
+type Edge[K comparable] struct {
+	From       K
+	To         K
+	LinkID     string
+	Attributes Attributes
+}
+
+// Edges returns an Edge for every link in the graph, ordered by the from and to nodes using the
+// OrderOptions and then by when the link was added
+func (g *Graph[K]) Edges() (edges []Edge[K]) {
+	for _, from := range g.Nodes() {
+		for _, to := range g.ordered(g.dependentMap[from]) {
+			edges = append(edges, g.edges(from, to)...)
+		}
+	}
+	return edges
+}
+
+// edges returns an Edge for each link from -> to
+func (g *Graph[K]) edges(from, to K) (edges []Edge[K]) {
+	links := g.linkMap[from][to]
+	if len(links) == 0 {
+		return []Edge[K]{{From: from, To: to}}
+	}
+	for _, l := range links {
+		edges = append(edges, Edge[K]{From: from, To: to, LinkID: l.id, Attributes: l.attrs.clone()})
+	}
+	return edges
+}
+
+// LinkIDs returns the IDs of all the links between two nodes in the order they were added
+func (g *Graph[K]) LinkIDs(from, to K) []string {
+	return g.linkIDs(from, to)
+}
@@ -0,0 +1,36 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestMultipleLinks(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("1", "start", "gateway"))
+	assert.NoError(t, g.AddLink("2", "gateway", "end"))
+	assert.NoError(t, g.AddLink("3", "gateway", "end"))
+	assert.NoError(t, g.AddLink("3", "gateway", "end")) // Already there
+	assert.NoError(t, g.DependOn("audit", "end"))
+	assert.NoError(t, g.SetLinkAttrByID("3", depgraph.AttrCondition, "amount > 10"))
+	assert.Error(t, g.SetLinkAttrByID("4", depgraph.AttrCondition, "amount > 10"))
+
+	assert.Equal(t, []string{"2", "3"}, g.LinkIDs("gateway", "end"))
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrCondition: "amount > 10"}, g.LinkAttrsByID("3"))
+	assert.Nil(t, g.LinkAttrsByID("2"))
+	assert.Equal(t, []depgraph.Edge[string]{
+		{From: "start", To: "gateway", LinkID: "1"},
+		{From: "gateway", To: "end", LinkID: "2"},
+		{From: "gateway", To: "end", LinkID: "3", Attributes: depgraph.Attributes{depgraph.AttrCondition: "amount > 10"}},
+		{From: "end", To: "audit"},
+	}, g.Edges())
+
+	actual := g.TopologicalSort()
+	assert.Len(t, actual, 4)
+	assert.Equal(t, "end", actual[2].Node)
+	assert.Equal(t, "2", actual[2].FromLinkID)
+	assert.Equal(t, []string{"2", "3"}, actual[2].FromLinkIDs)
+	assert.Empty(t, actual[3].FromLinkIDs)
+}
@@ -0,0 +1,227 @@
+package depgraph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// ExecuteOptions controls Execute.  Retries and Timeout can be set per node with the AttrRetries and
+// AttrTimeout node attributes, which can also be the whole numbers and duration strings of a loaded graph
+type ExecuteOptions struct {
+	// Workers is the most nodes run at once, 0 is runtime.GOMAXPROCS
+	Workers int
+	// IgnoreFailures carries on after a node fails and still runs the nodes depending on it, as if it had
+	// succeeded.  Otherwise the first failure cancels the run, use SkipDependents to carry on without them
+	IgnoreFailures bool
+	// SkipDependents carries on after a node fails, skipping every node depending on it while the rest run
+	SkipDependents bool
+	// Retries is how many times a failing node is tried again, it can't be negative
+	Retries int
+	// Backoff is the wait before the first retry, it doubles for each retry after that
+	Backoff time.Duration
+	// Timeout limits each attempt of a node through its context, 0 is no limit and it can't be negative
+	Timeout time.Duration
+}
+
+// NodeState is how a node finished during Execute
+type NodeState int
+
+const (
+	NodeNotRun    NodeState = iota // Never started because the run was cancelled
+	NodeSucceeded                  // fn returned nil
+	NodeFailed                     // fn returned an error on every attempt
+	NodeSkipped                    // Not run because a node it depends on failed
+)
+
+func (s NodeState) String() string {
+	switch s {
+	case NodeSucceeded:
+		return "succeeded"
+	case NodeFailed:
+		return "failed"
+	case NodeSkipped:
+		return "skipped"
+	}
+	return "not run"
+}
+
+// NodeResult is what happened to a node during Execute
+type NodeResult[K comparable] struct {
+	Node  K
+	State NodeState
+	// Ran is false when the node was never started
+	Ran bool
+	// Attempts is how many times fn was called
+	Attempts int
+	// Err is the last error returned by the node, the context error if it was not run or, when skipped,
+	// an error naming the failed node
+	Err        error
+	Start, End time.Time
+}
+
+// Execute calls fn for every node in the graph, a node is started as soon as all the nodes it depends on
+// have finished so there are no layer by layer barriers.  Nodes ready at the same time start in add order.
+// The results are in add order and the error joins the errors of the failed nodes.  A graph with cycles
+// returns a *CycleError, and a node with an invalid AttrRetries or AttrTimeout an error, without running
+// anything.  The graph must not be changed while Execute runs
+func Execute[K comparable](ctx context.Context, g *Graph[K], fn func(ctx context.Context, node K) error,
+	opts ExecuteOptions) ([]*NodeResult[K], error) {
+	if opts.Retries < 0 || opts.Timeout < 0 {
+		return nil, fmt.Errorf("retries %d and timeout %v can't be negative", opts.Retries, opts.Timeout)
+	}
+	if _, err := g.SortedLayersStrict(); err != nil {
+		return nil, err
+	}
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	nodes := g.nodesInAddOrder()
+	type policy struct {
+		retries int
+		timeout time.Duration
+	}
+	policies := make(map[K]policy, len(nodes))
+	for _, n := range nodes {
+		retries, timeout, err := g.executePolicy(n, opts)
+		if err != nil {
+			return nil, err
+		}
+		policies[n] = policy{retries: retries, timeout: timeout}
+	}
+	results := make(map[K]*NodeResult[K], len(nodes))
+	waitingOn := make(map[K]int, len(nodes))
+	var ready []K
+	for _, n := range nodes {
+		results[n] = &NodeResult[K]{Node: n}
+		waitingOn[n] = len(g.dependencyMap[n])
+		if waitingOn[n] == 0 {
+			ready = append(ready, n)
+		}
+	}
+	release := func(n K) {
+		for _, child := range g.inAddOrder(g.dependentMap[n]) {
+			if waitingOn[child]--; waitingOn[child] == 0 {
+				ready = append(ready, child)
+			}
+		}
+	}
+
+	type done struct {
+		node     K
+		attempts int
+		err      error
+	}
+	finished := make(chan done)
+	running := 0
+	var errs []error
+	for {
+		for running < workers && len(ready) > 0 && ctx.Err() == nil {
+			n := ready[0]
+			ready = ready[1:]
+			r := results[n]
+			if r.State == NodeSkipped {
+				release(n)
+				continue
+			}
+			r.Ran, r.Start = true, time.Now()
+			running++
+			p := policies[n]
+			go func() {
+				attempts, err := executeNode(ctx, n, fn, p.retries, opts.Backoff, p.timeout)
+				finished <- done{node: n, attempts: attempts, err: err}
+			}()
+		}
+		if running == 0 {
+			break
+		}
+		d := <-finished
+		running--
+		r := results[d.node]
+		r.End, r.Attempts, r.Err = time.Now(), d.attempts, d.err
+		r.State = NodeSucceeded
+		if d.err != nil {
+			r.State = NodeFailed
+			errs = append(errs, fmt.Errorf("node %v: %w", d.node, d.err))
+			switch {
+			case opts.SkipDependents:
+				for _, dependent := range g.inAddOrder(g.dependents(d.node)) {
+					if dr := results[dependent]; dr.State != NodeSkipped {
+						dr.State, dr.Err = NodeSkipped, fmt.Errorf("depends on failed node %v", d.node)
+					}
+				}
+			case !opts.IgnoreFailures:
+				cancel()
+			}
+		}
+		release(d.node)
+	}
+
+	report := make([]*NodeResult[K], len(nodes))
+	for i, n := range nodes {
+		report[i] = results[n]
+		if !report[i].Ran && report[i].State == NodeNotRun {
+			report[i].Err = ctx.Err()
+		}
+	}
+	return report, errors.Join(errs...)
+}
+
+// executePolicy returns the retries and timeout for a node, from its attributes or the options
+func (g *Graph[K]) executePolicy(n K, opts ExecuteOptions) (retries int, timeout time.Duration, err error) {
+	retries, timeout = opts.Retries, opts.Timeout
+	attrs := g.nodes[n].attrs
+	if r, ok := attrs[AttrRetries]; ok {
+		if retries, err = intAttr(r); err == nil && retries < 0 {
+			err = fmt.Errorf("%d is negative", retries)
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("node %v %s: %w", n, AttrRetries, err)
+		}
+	}
+	if t, ok := attrs[AttrTimeout]; ok {
+		if timeout, err = durationAttr(t); err == nil && timeout < 0 {
+			err = fmt.Errorf("%v is negative", timeout)
+		}
+		if err != nil {
+			return 0, 0, fmt.Errorf("node %v %s: %w", n, AttrTimeout, err)
+		}
+	}
+	return retries, timeout, nil
+}
+
+// executeNode calls fn until it succeeds, it has been retried retries times or the run is cancelled
+func executeNode[K comparable](ctx context.Context, n K, fn func(ctx context.Context, node K) error,
+	retries int, backoff, timeout time.Duration) (attempts int, err error) {
+	for {
+		attempts++
+		err = executeAttempt(ctx, n, fn, timeout)
+		if err == nil || attempts > retries || ctx.Err() != nil {
+			return attempts, err
+		}
+		if backoff > 0 {
+			select {
+			case <-time.After(backoff):
+			case <-ctx.Done():
+				return attempts, err
+			}
+			backoff *= 2
+		}
+	}
+}
+
+func executeAttempt[K comparable](ctx context.Context, n K, fn func(ctx context.Context, node K) error,
+	timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	return fn(ctx, n)
+}
@@ -0,0 +1,211 @@
+package depgraph_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+// executeGraph is a diamond, b and c depend on a and d depends on both, with e on its own
+func executeGraph(t *testing.T) *depgraph.Graph[string] {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.DependOn("b", "a"))
+	require.NoError(t, g.DependOn("c", "a"))
+	require.NoError(t, g.DependOn("d", "b"))
+	require.NoError(t, g.DependOn("d", "c"))
+	g.AddNode("e", 0, 0)
+	return g
+}
+
+func TestExecute(t *testing.T) {
+	g := executeGraph(t)
+	var running, most atomic.Int32
+	results, err := depgraph.Execute(context.Background(), g, func(ctx context.Context, node string) error {
+		n := running.Add(1)
+		defer running.Add(-1)
+		for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		return nil
+	}, depgraph.ExecuteOptions{Workers: 2})
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.LessOrEqual(t, most.Load(), int32(2))
+
+	byNode := make(map[string]*depgraph.NodeResult[string])
+	for i, r := range results {
+		assert.Equal(t, []string{"a", "b", "c", "d", "e"}[i], r.Node)
+		assert.True(t, r.Ran)
+		assert.NoError(t, r.Err)
+		byNode[r.Node] = r
+	}
+	for _, edge := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+		assert.False(t, byNode[edge[1]].Start.Before(byNode[edge[0]].End), "%v started before %v finished", edge[1], edge[0])
+	}
+}
+
+func TestExecuteNoBarriers(t *testing.T) {
+	// b only waits for a, not for the slow x in the same layer as a
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.DependOn("b", "a"))
+	require.NoError(t, g.DependOn("y", "x"))
+	var mu sync.Mutex
+	var order []string
+	_, err := depgraph.Execute(context.Background(), g, func(ctx context.Context, node string) error {
+		if node == "x" {
+			time.Sleep(50 * time.Millisecond)
+		}
+		mu.Lock()
+		order = append(order, node)
+		mu.Unlock()
+		return nil
+	}, depgraph.ExecuteOptions{Workers: 2})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "x", "y"}, order)
+}
+
+func TestExecuteErrors(t *testing.T) {
+	failed := errors.New("failed")
+	fn := func(ctx context.Context, node string) error {
+		if node == "b" {
+			return failed
+		}
+		return nil
+	}
+
+	// Fail fast, a then e are ready before b fails but nothing is started after
+	results, err := depgraph.Execute(context.Background(), executeGraph(t), fn, depgraph.ExecuteOptions{Workers: 1})
+	assert.ErrorIs(t, err, failed)
+	ran := make(map[string]bool)
+	for _, r := range results {
+		ran[r.Node] = r.Ran
+		if !r.Ran {
+			assert.ErrorIs(t, r.Err, context.Canceled)
+		}
+	}
+	assert.Equal(t, map[string]bool{"a": true, "b": true, "c": false, "d": false, "e": true}, ran)
+
+	// Ignoring failures everything runs, d even though b failed
+	results, err = depgraph.Execute(context.Background(), executeGraph(t), fn, depgraph.ExecuteOptions{Workers: 1, IgnoreFailures: true})
+	assert.ErrorIs(t, err, failed)
+	for _, r := range results {
+		assert.True(t, r.Ran)
+		if r.Node == "b" {
+			assert.ErrorIs(t, r.Err, failed)
+		} else {
+			assert.NoError(t, r.Err)
+		}
+	}
+
+	// Cycles
+	g := executeGraph(t)
+	require.NoError(t, g.DependOn("a", "d"))
+	_, err = depgraph.Execute(context.Background(), g, fn, depgraph.ExecuteOptions{})
+	var cycleErr *depgraph.CycleError[string]
+	assert.ErrorAs(t, err, &cycleErr)
+}
+
+func TestExecutePolicies(t *testing.T) {
+	g := executeGraph(t)
+	require.NoError(t, g.SetNodeAttr("c", depgraph.AttrRetries, 0))
+	require.NoError(t, g.SetNodeAttr("e", depgraph.AttrTimeout, time.Millisecond))
+	var mu sync.Mutex
+	calls := make(map[string]int)
+	results, err := depgraph.Execute(context.Background(), g, func(ctx context.Context, node string) error {
+		mu.Lock()
+		calls[node]++
+		call := calls[node]
+		mu.Unlock()
+		switch node {
+		case "a":
+			// Succeeds on the third attempt
+			if call < 3 {
+				return errors.New("not yet")
+			}
+		case "b", "c":
+			return errors.New("always fails")
+		case "e":
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	}, depgraph.ExecuteOptions{SkipDependents: true, Retries: 2, Backoff: time.Millisecond, Timeout: time.Second})
+	assert.Error(t, err)
+
+	type outcome struct {
+		State    depgraph.NodeState
+		Attempts int
+	}
+	got := make(map[string]outcome)
+	for _, r := range results {
+		got[r.Node] = outcome{r.State, r.Attempts}
+	}
+	assert.Equal(t, map[string]outcome{
+		"a": {depgraph.NodeSucceeded, 3},
+		"b": {depgraph.NodeFailed, 3},
+		"c": {depgraph.NodeFailed, 1},
+		"d": {depgraph.NodeSkipped, 0},
+		"e": {depgraph.NodeFailed, 3},
+	}, got)
+	assert.ErrorIs(t, results[4].Err, context.DeadlineExceeded)
+	assert.False(t, results[3].Ran)
+	assert.Equal(t, "skipped", results[3].State.String())
+}
+
+func TestExecuteLoadedPolicies(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("a", 0, 0)
+	require.NoError(t, g.SetNodeAttr("a", depgraph.AttrRetries, 1))
+	require.NoError(t, g.SetNodeAttr("a", depgraph.AttrTimeout, time.Millisecond))
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+
+	// Reloaded, and hand written with a plain number and a duration string
+	reloaded := depgraph.NewGraph[string]()
+	require.NoError(t, json.Unmarshal(data, reloaded))
+	written := depgraph.NewGraph[string]()
+	require.NoError(t, json.Unmarshal([]byte(`{"version": 1, "nodes": [
+		{"id": "a", "attributes": {"retries": 1, "timeout": "1ms"}}
+	]}`), written))
+
+	for _, loaded := range []*depgraph.Graph[string]{reloaded, written} {
+		results, err := depgraph.Execute(context.Background(), loaded, func(ctx context.Context, node string) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, depgraph.ExecuteOptions{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		require.Len(t, results, 1)
+		assert.Equal(t, 2, results[0].Attempts)
+	}
+
+	noop := func(ctx context.Context, node string) error { return nil }
+	for _, attr := range []struct {
+		key   string
+		value any
+	}{
+		{depgraph.AttrRetries, 1.5},
+		{depgraph.AttrRetries, -1},
+		{depgraph.AttrRetries, float64(1 << 63)},
+		{depgraph.AttrRetries, uint64(1 << 63)},
+		{depgraph.AttrTimeout, "-1s"},
+	} {
+		bad := depgraph.NewGraph[string]()
+		bad.AddNode("a", 0, 0)
+		require.NoError(t, bad.SetNodeAttr("a", attr.key, attr.value))
+		results, err := depgraph.Execute(context.Background(), bad, noop, depgraph.ExecuteOptions{})
+		assert.ErrorContains(t, err, "node a "+attr.key, attr.value)
+		assert.Nil(t, results)
+	}
+	_, err = depgraph.Execute(context.Background(), g, noop, depgraph.ExecuteOptions{Retries: -1})
+	assert.Error(t, err)
+	_, err = depgraph.Execute(context.Background(), g, noop, depgraph.ExecuteOptions{Timeout: -time.Second})
+	assert.Error(t, err)
+}
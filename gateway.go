@@ -0,0 +1,74 @@
+package depgraph
+
+// GatewayPair is a node the route splits at, a gateway in BPMN, and the node where the branches join again
+type GatewayPair[K comparable] struct {
+	Split K
+	// Join is the first node every branch out of Split reaches, nil when a branch never joins the others
+	Join *K
+}
+
+// GatewayPairs pairs every node with more than one dependent with the node its branches join at.  Where
+// several nodes are reached by every branch the join is the one that doesn't depend on the others, the first
+// added if there is more than one, so a join inside a cycle has no pair.  Pairs are in the order the split
+// nodes were added.  The reachability index is used if there is one, otherwise it's built for the call
+func (g *Graph[K]) GatewayPairs() (pairs []GatewayPair[K]) {
+	gj := g.gatewayJoins()
+	for _, split := range gj.nodes {
+		if g.OutDegree(split) >= 2 {
+			pairs = append(pairs, GatewayPair[K]{Split: split, Join: gj.join(split)})
+		}
+	}
+	return pairs
+}
+
+// gatewayJoins finds the joins of split nodes using the transitive closure of the graph
+type gatewayJoins[K comparable] struct {
+	g     *Graph[K]
+	ri    *reachIndex[K]
+	nodes []K
+}
+
+func (g *Graph[K]) gatewayJoins() *gatewayJoins[K] {
+	ri := g.reach
+	if ri == nil {
+		ri = g.reachabilityIndex()
+	}
+	return &gatewayJoins[K]{g: g, ri: ri, nodes: g.nodesInAddOrder()}
+}
+
+// join returns the first node every branch out of split reaches that doesn't depend on another such node.
+// Everything depending on a node reached by every branch is also reached by every branch, so it's enough
+// to check the parents
+func (gj *gatewayJoins[K]) join(split K) *K {
+	var common bitset
+	first := true
+	for child := range gj.g.dependentMap[split] {
+		reached := append(bitset(nil), gj.ri.reach[gj.ri.index[child]]...)
+		reached.set(gj.ri.index[child])
+		if first {
+			common, first = reached, false
+		} else {
+			common.and(reached)
+		}
+	}
+	inCommon := func(n K) bool {
+		i, ok := gj.ri.index[n]
+		return ok && n != split && common.has(i)
+	}
+	for _, n := range gj.nodes {
+		if !inCommon(n) {
+			continue
+		}
+		joined := true
+		for parent := range gj.g.dependencyMap[n] {
+			if parent != n && inCommon(parent) {
+				joined = false
+				break
+			}
+		}
+		if joined {
+			return &n
+		}
+	}
+	return nil
+}
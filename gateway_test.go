@@ -0,0 +1,41 @@
+package depgraph_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+	"github.com/timdadd/depgraph/bpmn"
+)
+
+func TestGatewayPairs(t *testing.T) {
+	g := treeGraph(t)
+	require.NoError(t, g.DependOn("join", "d"))
+	join, end := "join", "end"
+	assert.Equal(t, []depgraph.GatewayPair[string]{{Split: "split", Join: &join}}, g.GatewayPairs())
+	// A branch going straight to the end skips the join
+	require.NoError(t, g.DependOn("end", "split"))
+	assert.Equal(t, []depgraph.GatewayPair[string]{{Split: "split", Join: &end}}, g.GatewayPairs())
+
+	f, err := os.Open("bpmn/TestTopologicalSort005.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	g, err = bpmn.Load(f)
+	require.NoError(t, err)
+	joins := make(map[string]*string)
+	for _, pair := range g.GatewayPairs() {
+		joins[pair.Split] = pair.Join
+	}
+	require.Contains(t, joins, "Gateway_1iqkz3r") // SIM type?
+	assert.Equal(t, "Gateway_1uv7159", *joins["Gateway_1iqkz3r"])
+	require.Contains(t, joins, "Gateway_19ib8qw") // Order includes Device?
+	assert.Equal(t, "Gateway_0y26mfn", *joins["Gateway_19ib8qw"])
+	assert.Nil(t, joins["Gateway_0poi7lh"])
+
+	// The same pairs with a reachability index
+	pairs := g.GatewayPairs()
+	g.BuildReachabilityIndex()
+	assert.Equal(t, pairs, g.GatewayPairs())
+}
@@ -0,0 +1,295 @@
+package depgraph
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// yFiles shape size used when writing GraphML, yEd needs one to draw the node
+const (
+	graphMLNodeWidth  = 100
+	graphMLNodeHeight = 50
+)
+
+type graphMLDoc struct {
+	Keys   []graphMLKey   `xml:"key"`
+	Graphs []graphMLGraph `xml:"graph"`
+}
+
+type graphMLKey struct {
+	ID   string `xml:"id,attr"`
+	For  string `xml:"for,attr"`
+	Name string `xml:"attr.name,attr"`
+	Type string `xml:"attr.type,attr"`
+}
+
+type graphMLGraph struct {
+	Nodes []graphMLNode `xml:"node"`
+	Edges []graphMLEdge `xml:"edge"`
+}
+
+type graphMLNode struct {
+	ID   string        `xml:"id,attr"`
+	Data []graphMLData `xml:"data"`
+}
+
+type graphMLEdge struct {
+	ID     string        `xml:"id,attr"`
+	Source string        `xml:"source,attr"`
+	Target string        `xml:"target,attr"`
+	Data   []graphMLData `xml:"data"`
+}
+
+// graphMLData is either a value or, for yFiles, a node (ShapeNode, GenericNode etc.) or edge graphic
+type graphMLData struct {
+	Key      string          `xml:"key,attr"`
+	Value    string          `xml:",chardata"`
+	Graphics *yFilesGraphics `xml:",any"`
+}
+
+type yFilesGraphics struct {
+	Geometry *struct {
+		X float32 `xml:"x,attr"`
+		Y float32 `xml:"y,attr"`
+	} `xml:"Geometry"`
+	NodeLabels []string `xml:"NodeLabel"`
+	EdgeLabels []string `xml:"EdgeLabel"`
+}
+
+// WriteGraphML writes the graph as GraphML that yEd and Gephi can read.  Node IDs are the nodes formatted
+// with fmt.Sprint, it's an error if two nodes format the same, and x,y is written as yFiles geometry along
+// with the label.  There is an edge for each link with the linkID as the edge id and attributes are written
+// as <data> with a <key> for each attribute name, integers including time.Duration as a long
+func (g *Graph[K]) WriteGraphML(w io.Writer) error {
+	nodes := g.nodesInAddOrder()
+	ids := make(map[string]K, len(nodes))
+	for _, n := range nodes {
+		id := fmt.Sprint(n)
+		if other, ok := ids[id]; ok {
+			return fmt.Errorf("nodes %#v and %#v both have GraphML id %q", other, n, id)
+		}
+		ids[id] = n
+	}
+	edges := g.Edges()
+	nodeKeys := make(map[string]string)
+	for _, n := range nodes {
+		addGraphMLKeys(nodeKeys, g.nodes[n].attrs)
+	}
+	edgeKeys := make(map[string]string)
+	for _, e := range edges {
+		addGraphMLKeys(edgeKeys, e.Attributes)
+	}
+	nodeKeyIDs := graphMLKeyIDs(nodeKeys, "n")
+	edgeKeyIDs := graphMLKeyIDs(edgeKeys, "e")
+
+	var b bytes.Buffer
+	b.WriteString(xml.Header)
+	b.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">` + "\n")
+	b.WriteString(`  <key id="d0" for="node" yfiles.type="nodegraphics"/>` + "\n")
+	b.WriteString(`  <key id="d1" for="edge" yfiles.type="edgegraphics"/>` + "\n")
+	writeKeys := func(keys, ids map[string]string, kind string) {
+		for _, name := range sortedKeys(keys) {
+			fmt.Fprintf(&b, `  <key id="%s" for="%s" attr.name="%s" attr.type="%s"/>`+"\n", ids[name], kind, xmlEscape(name), keys[name])
+		}
+	}
+	writeKeys(nodeKeys, nodeKeyIDs, "node")
+	writeKeys(edgeKeys, edgeKeyIDs, "edge")
+	writeData := func(attrs Attributes, ids map[string]string) {
+		for _, name := range sortedKeys(attrs) {
+			fmt.Fprintf(&b, `      <data key="%s">%s</data>`+"\n", ids[name], xmlEscape(graphMLText(attrs[name])))
+		}
+	}
+	b.WriteString(`  <graph id="G" edgedefault="directed">` + "\n")
+	for _, n := range nodes {
+		nd := g.nodes[n]
+		fmt.Fprintf(&b, `    <node id="%s">`+"\n", xmlEscape(fmt.Sprint(n)))
+		writeData(nd.attrs, nodeKeyIDs)
+		fmt.Fprintf(&b, `      <data key="d0"><y:ShapeNode><y:Geometry x="%g" y="%g" width="%d" height="%d"/><y:NodeLabel>%s</y:NodeLabel></y:ShapeNode></data>`+"\n",
+			nd.x, nd.y, graphMLNodeWidth, graphMLNodeHeight, xmlEscape(g.label(n)))
+		b.WriteString("    </node>\n")
+	}
+	for _, e := range edges {
+		b.WriteString("    <edge")
+		if e.LinkID != "" {
+			fmt.Fprintf(&b, ` id="%s"`, xmlEscape(e.LinkID))
+		}
+		fmt.Fprintf(&b, ` source="%s" target="%s">`+"\n", xmlEscape(fmt.Sprint(e.From)), xmlEscape(fmt.Sprint(e.To)))
+		writeData(e.Attributes, edgeKeyIDs)
+		if label, ok := e.Attributes[AttrLabel]; ok {
+			fmt.Fprintf(&b, `      <data key="d1"><y:PolyLineEdge><y:EdgeLabel>%s</y:EdgeLabel></y:PolyLineEdge></data>`+"\n", xmlEscape(fmt.Sprint(label)))
+		}
+		b.WriteString("    </edge>\n")
+	}
+	b.WriteString("  </graph>\n</graphml>\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ReadGraphML reads the nodes and edges of the first graph in a GraphML document.  Node x,y come from
+// yFiles geometry, or x and y data, edge ids become linkIDs and other data becomes attributes converted
+// using the key's attr.type.  A yFiles node or edge label becomes the AttrLabel when there is no label data
+func ReadGraphML(r io.Reader) (*Graph[string], error) {
+	var doc graphMLDoc
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("decoding graphml: %w", err)
+	}
+	g := NewGraph[string]()
+	if len(doc.Graphs) == 0 {
+		return g, nil
+	}
+	keys := make(map[string]graphMLKey, len(doc.Keys))
+	for _, k := range doc.Keys {
+		keys[k.ID] = k
+	}
+	graph := doc.Graphs[0]
+	for _, gn := range graph.Nodes {
+		var x, y float32
+		attrs := make(Attributes)
+		var label string
+		for _, d := range gn.Data {
+			if d.Graphics != nil {
+				if d.Graphics.Geometry != nil {
+					x, y = d.Graphics.Geometry.X, d.Graphics.Geometry.Y
+				}
+				if len(d.Graphics.NodeLabels) > 0 {
+					label = strings.TrimSpace(d.Graphics.NodeLabels[0])
+				}
+				continue
+			}
+			k, ok := keys[d.Key]
+			if !ok || k.Name == "" {
+				continue
+			}
+			value := graphMLValue(d.Value, k.Type)
+			f, isFloat := value.(float64)
+			switch {
+			case k.Name == "x" && isFloat:
+				x = float32(f)
+			case k.Name == "y" && isFloat:
+				y = float32(f)
+			default:
+				attrs[k.Name] = value
+			}
+		}
+		if _, ok := attrs[AttrLabel]; !ok && label != "" && label != gn.ID {
+			attrs[AttrLabel] = label
+		}
+		g.AddNode(gn.ID, x, y)
+		if len(attrs) > 0 {
+			g.nodes[gn.ID].attrs = attrs
+		}
+	}
+	for _, ge := range graph.Edges {
+		if err := g.DependOn(ge.Target, ge.Source); err != nil {
+			return nil, fmt.Errorf("edge %v from %v to %v: %w", ge.ID, ge.Source, ge.Target, err)
+		}
+		attrs := make(Attributes)
+		var label string
+		for _, d := range ge.Data {
+			if d.Graphics != nil {
+				if len(d.Graphics.EdgeLabels) > 0 {
+					label = strings.TrimSpace(d.Graphics.EdgeLabels[0])
+				}
+			} else if k, ok := keys[d.Key]; ok && k.Name != "" {
+				attrs[k.Name] = graphMLValue(d.Value, k.Type)
+			}
+		}
+		if _, ok := attrs[AttrLabel]; !ok && label != "" {
+			attrs[AttrLabel] = label
+		}
+		if ge.ID == "" && len(attrs) == 0 {
+			continue
+		}
+		l, err := g.addLink(ge.Source, ge.Target, ge.ID)
+		if err != nil {
+			return nil, fmt.Errorf("edge %v from %v to %v: %w", ge.ID, ge.Source, ge.Target, err)
+		}
+		if len(attrs) > 0 {
+			l.attrs = attrs
+		}
+	}
+	return g, nil
+}
+
+// addGraphMLKeys records the GraphML attr.type of each attribute, the first type seen for a name wins
+func addGraphMLKeys(keys map[string]string, attrs Attributes) {
+	for name, value := range attrs {
+		if _, ok := keys[name]; ok {
+			continue
+		}
+		switch reflect.ValueOf(value).Kind() {
+		case reflect.Bool:
+			keys[name] = "boolean"
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			keys[name] = "long"
+		case reflect.Float32, reflect.Float64:
+			keys[name] = "double"
+		default:
+			keys[name] = "string"
+		}
+	}
+}
+
+// graphMLText formats a <data> value to match the attr.type from addGraphMLKeys
+func graphMLText(value any) string {
+	v := reflect.ValueOf(value)
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'g', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+func graphMLKeyIDs(keys map[string]string, prefix string) map[string]string {
+	ids := make(map[string]string, len(keys))
+	for i, name := range sortedKeys(keys) {
+		ids[name] = fmt.Sprintf("%s%d", prefix, i)
+	}
+	return ids
+}
+
+// graphMLValue converts a <data> value to its attr.type, leaving it as a string if it doesn't convert
+func graphMLValue(value, attrType string) any {
+	value = strings.TrimSpace(value)
+	switch attrType {
+	case "boolean":
+		if b, err := strconv.ParseBool(value); err == nil {
+			return b
+		}
+	case "int", "long":
+		if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return int(i)
+		}
+	case "float", "double":
+		if f, err := strconv.ParseFloat(value, 64); err == nil {
+			return f
+		}
+	}
+	return value
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	_ = xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
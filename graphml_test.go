@@ -0,0 +1,106 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestGraphMLRoundTrip(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("start", 10, 20)
+	g.AddNode("check", 150.5, 20)
+	assert.NoError(t, g.AddLink("Flow_1", "start", "check"))
+	assert.NoError(t, g.AddLink("Flow_2", "check", "end"))
+	assert.NoError(t, g.AddLink("Flow_3", "check", "end"))
+	assert.NoError(t, g.DependOn("audit", "end"))
+	assert.NoError(t, g.SetNodeAttr("check", depgraph.AttrLabel, "Check <Order>"))
+	assert.NoError(t, g.SetNodeAttr("check", "retries", 3))
+	assert.NoError(t, g.SetNodeAttr("check", "automated", true))
+	assert.NoError(t, g.SetLinkAttrByID("Flow_2", depgraph.AttrCondition, `status == "ok"`))
+	assert.NoError(t, g.SetLinkAttrByID("Flow_3", depgraph.AttrLabel, "Retry"))
+
+	var b strings.Builder
+	require.NoError(t, g.WriteGraphML(&b))
+	assert.Contains(t, b.String(), `<y:Geometry x="150.5" y="20" width="100" height="50"/><y:NodeLabel>Check &lt;Order&gt;</y:NodeLabel>`)
+	assert.Contains(t, b.String(), `<key id="n1" for="node" attr.name="label" attr.type="string"/>`)
+
+	loaded, err := depgraph.ReadGraphML(strings.NewReader(b.String()))
+	require.NoError(t, err)
+	assert.Equal(t, g.Edges(), loaded.Edges())
+	assert.Equal(t, g.NodeAttrs("check"), loaded.NodeAttrs("check"))
+	assert.Nil(t, loaded.NodeAttrs("start"))
+	assert.Equal(t, g.TopologicalSort(), loaded.TopologicalSort())
+}
+
+func TestGraphMLDuration(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("task", 0, 0)
+	assert.NoError(t, g.SetNodeAttr("task", depgraph.AttrDuration, 5*time.Minute))
+
+	var b strings.Builder
+	require.NoError(t, g.WriteGraphML(&b))
+	assert.Contains(t, b.String(), `attr.name="duration" attr.type="long"`)
+	assert.Contains(t, b.String(), `<data key="n0">300000000000</data>`)
+
+	loaded, err := depgraph.ReadGraphML(strings.NewReader(b.String()))
+	require.NoError(t, err)
+	d, _ := loaded.NodeAttr("task", depgraph.AttrDuration)
+	assert.Equal(t, int(5*time.Minute), d)
+}
+
+func TestGraphMLNodeIDClash(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.DependOn(1, "1"))
+	assert.ErrorContains(t, g.WriteGraphML(&strings.Builder{}), `both have GraphML id "1"`)
+}
+
+func TestReadGraphML(t *testing.T) {
+	// As written by yEd, with Gephi style x and y for the node yEd didn't draw
+	g, err := depgraph.ReadGraphML(strings.NewReader(`<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<graphml xmlns="http://graphml.graphdrawing.org/xmlns" xmlns:y="http://www.yworks.com/xml/graphml">
+  <key for="node" id="d6" yfiles.type="nodegraphics"/>
+  <key for="node" id="d7" attr.name="x" attr.type="float"/>
+  <key for="node" id="d8" attr.name="y" attr.type="float"/>
+  <key for="edge" id="d10" yfiles.type="edgegraphics"/>
+  <graph edgedefault="directed" id="G">
+    <node id="n0">
+      <data key="d6">
+        <y:GenericNode configuration="com.yworks.bpmn.Activity">
+          <y:Geometry height="55.0" width="85.0" x="310.0" y="225.0"/>
+          <y:NodeLabel alignment="center">Check Order</y:NodeLabel>
+        </y:GenericNode>
+      </data>
+    </node>
+    <node id="n1">
+      <data key="d7">400</data>
+      <data key="d8">225.5</data>
+    </node>
+    <edge id="e0" source="n0" target="n1">
+      <data key="d10">
+        <y:PolyLineEdge><y:EdgeLabel>Valid</y:EdgeLabel></y:PolyLineEdge>
+      </data>
+    </edge>
+  </graph>
+</graphml>`))
+	require.NoError(t, err)
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrLabel: "Check Order"}, g.NodeAttrs("n0"))
+	assert.Nil(t, g.NodeAttrs("n1"))
+	assert.Equal(t, []depgraph.Edge[string]{
+		{From: "n0", To: "n1", LinkID: "e0", Attributes: depgraph.Attributes{depgraph.AttrLabel: "Valid"}},
+	}, g.Edges())
+
+	var b strings.Builder
+	require.NoError(t, g.WriteGraphML(&b))
+	assert.Contains(t, b.String(), `<y:Geometry x="310" y="225" width="100" height="50"/>`)
+	assert.Contains(t, b.String(), `<y:Geometry x="400" y="225.5" width="100" height="50"/>`)
+
+	_, err = depgraph.ReadGraphML(strings.NewReader(`<graphml><graph><node id="a"/><edge source="a" target="a"/></graph></graphml>`))
+	assert.Error(t, err)
+	_, err = depgraph.ReadGraphML(strings.NewReader(`<graphml>`))
+	assert.Error(t, err)
+}
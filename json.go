@@ -0,0 +1,266 @@
+package depgraph
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// JSONVersion is the version of the JSON schema written by MarshalJSON
+//
+// Version 1 is
+//
+//	{
+//	  "version": 1,
+//	  "addCount": 3,            // Nodes ever added, so new nodes carry on the addOrder
+//	  "nodes": [                // In addOrder
+//	    {"id": "start", "x": 10, "y": 20, "addOrder": 0, "attributes": {"label": "Start"}},
+//	    {"id": "end", "x": 0, "y": 0, "addOrder": 2, "attributes": {"duration": {"type": "duration", "value": "5m0s"}}}
+//	  ],
+//	  "edges": [                // One per dependency, parent (from) -> child (to)
+//	    {"from": "start", "to": "end", "links": [{"id": "Flow_1", "attributes": {"label": "Yes"}}]}
+//	  ]
+//	}
+//
+// So a graph loads back exactly, attribute values that JSON can't tell apart are written with their type.
+// Strings, bools, float64 and nil are plain JSON, ints, uints, float32, time.Duration and time.Time are
+// {"type": "int", "value": 3} and anything else can't be marshalled.  Node IDs of a Graph[any] are written
+// the same way, those of other graphs are plain JSON and must unmarshal back to the same K
+const JSONVersion = 1
+
+type jsonGraph[K comparable] struct {
+	Version  int           `json:"version"`
+	AddCount int           `json:"addCount"`
+	Nodes    []jsonNode[K] `json:"nodes"`
+	Edges    []jsonEdge[K] `json:"edges"`
+}
+
+type jsonNode[K comparable] struct {
+	ID         jsonKey[K]     `json:"id"`
+	X          float32        `json:"x"`
+	Y          float32        `json:"y"`
+	AddOrder   int            `json:"addOrder"`
+	Attributes jsonAttributes `json:"attributes,omitempty"`
+}
+
+type jsonEdge[K comparable] struct {
+	From  jsonKey[K] `json:"from"`
+	To    jsonKey[K] `json:"to"`
+	Links []jsonLink `json:"links,omitempty"`
+}
+
+type jsonLink struct {
+	ID         string         `json:"id"`
+	Attributes jsonAttributes `json:"attributes,omitempty"`
+}
+
+// jsonKey is a node ID, typed when K is an interface so a Graph[any] gets the same IDs back
+type jsonKey[K comparable] struct {
+	id K
+}
+
+func (k jsonKey[K]) MarshalJSON() ([]byte, error) {
+	if reflect.TypeFor[K]().Kind() != reflect.Interface {
+		return json.Marshal(k.id)
+	}
+	return marshalJSONValue(k.id)
+}
+
+func (k *jsonKey[K]) UnmarshalJSON(data []byte) error {
+	if reflect.TypeFor[K]().Kind() != reflect.Interface {
+		return json.Unmarshal(data, &k.id)
+	}
+	v, err := unmarshalJSONValue(data)
+	if err != nil {
+		return err
+	}
+	// Checked before the id goes anywhere near a map, a []any id would panic
+	if v == nil || !reflect.TypeOf(v).Comparable() {
+		return fmt.Errorf("node id %s is not comparable", data)
+	}
+	id, ok := v.(K)
+	if !ok {
+		return fmt.Errorf("node id %s is not a %v", data, reflect.TypeFor[K]())
+	}
+	k.id = id
+	return nil
+}
+
+// jsonAttributes are Attributes with typed values
+type jsonAttributes Attributes
+
+func (a jsonAttributes) MarshalJSON() ([]byte, error) {
+	values := make(map[string]json.RawMessage, len(a))
+	for key, value := range a {
+		data, err := marshalJSONValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("attribute %q: %w", key, err)
+		}
+		values[key] = data
+	}
+	return json.Marshal(values)
+}
+
+func (a *jsonAttributes) UnmarshalJSON(data []byte) error {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(data, &values); err != nil {
+		return err
+	}
+	*a = make(jsonAttributes, len(values))
+	for key, data := range values {
+		value, err := unmarshalJSONValue(data)
+		if err != nil {
+			return fmt.Errorf("attribute %q: %w", key, err)
+		}
+		(*a)[key] = value
+	}
+	return nil
+}
+
+type jsonTypedValue struct {
+	Type  string          `json:"type"`
+	Value json.RawMessage `json:"value"`
+}
+
+// marshalJSONValue writes v so unmarshalJSONValue gives back the same type
+func marshalJSONValue(v any) ([]byte, error) {
+	var typed any
+	switch v := v.(type) {
+	case nil, string, bool, float64:
+		return json.Marshal(v)
+	case time.Duration:
+		typed = v.String()
+	case time.Time:
+		typed = v.Format(time.RFC3339Nano)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32:
+		typed = v
+	default:
+		return nil, fmt.Errorf("%T can't be written to JSON and read back", v)
+	}
+	value, err := json.Marshal(typed)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(jsonTypedValue{Type: fmt.Sprintf("%T", v), Value: value})
+}
+
+func unmarshalJSONValue(data []byte) (any, error) {
+	if !bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var v any
+		err := json.Unmarshal(data, &v)
+		return v, err
+	}
+	var tv jsonTypedValue
+	if err := json.Unmarshal(data, &tv); err != nil {
+		return nil, err
+	}
+	switch tv.Type {
+	case "time.Duration":
+		var s string
+		if err := json.Unmarshal(tv.Value, &s); err != nil {
+			return nil, err
+		}
+		return time.ParseDuration(s)
+	case "time.Time":
+		var s string
+		if err := json.Unmarshal(tv.Value, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+	case "int":
+		return unmarshalAs[int](tv.Value)
+	case "int8":
+		return unmarshalAs[int8](tv.Value)
+	case "int16":
+		return unmarshalAs[int16](tv.Value)
+	case "int32":
+		return unmarshalAs[int32](tv.Value)
+	case "int64":
+		return unmarshalAs[int64](tv.Value)
+	case "uint":
+		return unmarshalAs[uint](tv.Value)
+	case "uint8":
+		return unmarshalAs[uint8](tv.Value)
+	case "uint16":
+		return unmarshalAs[uint16](tv.Value)
+	case "uint32":
+		return unmarshalAs[uint32](tv.Value)
+	case "uint64":
+		return unmarshalAs[uint64](tv.Value)
+	case "float32":
+		return unmarshalAs[float32](tv.Value)
+	}
+	return nil, fmt.Errorf("unknown value type %q", tv.Type)
+}
+
+func unmarshalAs[T any](data []byte) (any, error) {
+	var v T
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// MarshalJSON writes the graph using the JSONVersion schema
+func (g *Graph[K]) MarshalJSON() ([]byte, error) {
+	jg := jsonGraph[K]{
+		Version:  JSONVersion,
+		AddCount: g.addCount,
+		Nodes:    []jsonNode[K]{},
+		Edges:    []jsonEdge[K]{},
+	}
+	nodes := g.nodesInAddOrder()
+	for _, n := range nodes {
+		nd := g.nodes[n]
+		jg.Nodes = append(jg.Nodes, jsonNode[K]{ID: jsonKey[K]{n}, X: nd.x, Y: nd.y, AddOrder: nd.addOrder, Attributes: jsonAttributes(nd.attrs)})
+	}
+	for _, from := range nodes {
+		for _, to := range g.inAddOrder(g.dependentMap[from]) {
+			je := jsonEdge[K]{From: jsonKey[K]{from}, To: jsonKey[K]{to}}
+			for _, l := range g.linkMap[from][to] {
+				je.Links = append(je.Links, jsonLink{ID: l.id, Attributes: jsonAttributes(l.attrs)})
+			}
+			jg.Edges = append(jg.Edges, je)
+		}
+	}
+	return json.Marshal(jg)
+}
+
+// UnmarshalJSON replaces the graph with one written by MarshalJSON, keeping the OrderOptions
+func (g *Graph[K]) UnmarshalJSON(data []byte) error {
+	var jg jsonGraph[K]
+	if err := json.Unmarshal(data, &jg); err != nil {
+		return err
+	}
+	if jg.Version != JSONVersion {
+		return fmt.Errorf("unsupported depgraph JSON version %d", jg.Version)
+	}
+	loaded := NewGraph[K]()
+	for _, jn := range jg.Nodes {
+		id := jn.ID.id
+		loaded.nodes[id] = &node[K]{id: id, x: jn.X, y: jn.Y, addOrder: jn.AddOrder, attrs: Attributes(jn.Attributes)}
+	}
+	loaded.addCount = max(jg.AddCount, len(jg.Nodes))
+	for _, je := range jg.Edges {
+		from, to := je.From.id, je.To.id
+		if _, ok := loaded.nodes[from]; !ok {
+			return fmt.Errorf("edge from unknown node %v", from)
+		}
+		if _, ok := loaded.nodes[to]; !ok {
+			return fmt.Errorf("edge to unknown node %v", to)
+		}
+		if err := loaded.DependOn(to, from); err != nil {
+			return err
+		}
+		for _, jl := range je.Links {
+			l, err := loaded.addLink(from, to, jl.ID)
+			if err != nil {
+				return err
+			}
+			l.attrs = Attributes(jl.Attributes)
+		}
+	}
+	loaded.order = g.order
+	*g = *loaded
+	return nil
+}
@@ -0,0 +1,101 @@
+package depgraph_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+	"github.com/timdadd/depgraph/bpmn"
+)
+
+func TestJSONRoundTrip(t *testing.T) {
+	f, err := os.Open("bpmn/TestTopologicalSort005.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	g, err := bpmn.Load(f)
+	require.NoError(t, err)
+	assert.NoError(t, g.DependOn("Extra", "Event_1b30bns")) // A dependency without a link
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	var loaded depgraph.Graph[string]
+	require.NoError(t, json.Unmarshal(data, &loaded))
+
+	reloaded, err := json.Marshal(&loaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(reloaded))
+	assert.Equal(t, g.Edges(), loaded.Edges())
+	assert.Equal(t, g.TopologicalSort(), loaded.TopologicalSort())
+
+	// New nodes carry on from the loaded addOrder
+	loaded.AddNode("New", 0, 0)
+	g.AddNode("New", 0, 0)
+	data, err = json.Marshal(g)
+	require.NoError(t, err)
+	reloaded, err = json.Marshal(&loaded)
+	require.NoError(t, err)
+	assert.JSONEq(t, string(data), string(reloaded))
+}
+
+func TestJSONSchema(t *testing.T) {
+	g := depgraph.New()
+	g.AddNode("start", 10, 20)
+	assert.NoError(t, g.AddLink("Flow_1", "start", "end"))
+	assert.NoError(t, g.SetNodeAttr("start", depgraph.AttrLabel, "Start"))
+	assert.NoError(t, g.SetLinkAttr("start", "end", depgraph.AttrLabel, "Yes"))
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"version": 1,
+		"addCount": 2,
+		"nodes": [
+			{"id": "start", "x": 10, "y": 20, "addOrder": 0, "attributes": {"label": "Start"}},
+			{"id": "end", "x": 0, "y": 0, "addOrder": 1}
+		],
+		"edges": [
+			{"from": "start", "to": "end", "links": [{"id": "Flow_1", "attributes": {"label": "Yes"}}]}
+		]
+	}`, string(data))
+
+	loaded := depgraph.New()
+	assert.Error(t, json.Unmarshal([]byte(`{"version": 2}`), loaded))
+	assert.Error(t, json.Unmarshal([]byte(`{"version": 1, "edges": [{"from": "a", "to": "b"}]}`), loaded))
+	assert.Error(t, json.Unmarshal([]byte(`{"version": 1, "nodes": [{"id": "a"}], "edges": [{"from": "a", "to": "a"}]}`), loaded))
+	assert.NoError(t, json.Unmarshal(data, loaded))
+	assert.Equal(t, g.TopologicalSort(), loaded.TopologicalSort())
+}
+
+func TestJSONTypedValues(t *testing.T) {
+	g := depgraph.New()
+	assert.NoError(t, g.AddLink("Flow_1", "1", 1))
+	assert.NoError(t, g.SetNodeAttr("1", depgraph.AttrDuration, 5*time.Minute))
+	assert.NoError(t, g.SetNodeAttr("1", depgraph.AttrRetries, 3))
+	assert.NoError(t, g.SetNodeAttr("1", depgraph.AttrTimeout, 90*time.Second))
+	assert.NoError(t, g.SetNodeAttr(1, "weight", float32(0.5)))
+	assert.NoError(t, g.SetLinkAttr("1", 1, depgraph.AttrDelay, time.Second))
+
+	data, err := json.Marshal(g)
+	require.NoError(t, err)
+	loaded := depgraph.New()
+	require.NoError(t, json.Unmarshal(data, loaded))
+	assert.Equal(t, g.NodeAttrs("1"), loaded.NodeAttrs("1"))
+	assert.Equal(t, g.NodeAttrs(1), loaded.NodeAttrs(1))
+	assert.Equal(t, g.LinkAttrs("1", 1), loaded.LinkAttrs("1", 1))
+	assert.Equal(t, g.Edges(), loaded.Edges())
+
+	// Values that wouldn't load back the same are rejected
+	assert.NoError(t, g.SetNodeAttr(1, "tags", []string{"a"}))
+	_, err = json.Marshal(g)
+	assert.ErrorContains(t, err, `attribute "tags"`)
+
+	// IDs that can't be map keys are rejected
+	for _, id := range []string{`[1]`, `{"a": 1}`, `{"type": "int", "value": [1]}`, `null`} {
+		err := json.Unmarshal([]byte(`{"version": 1, "nodes": [{"id": `+id+`}]}`), depgraph.New())
+		assert.Error(t, err, id)
+	}
+}
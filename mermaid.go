@@ -0,0 +1,85 @@
+package depgraph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// MermaidOptions controls WriteMermaidFlowchart
+type MermaidOptions struct {
+	// Direction of the flowchart, TD, LR, BT or RL.  TD when empty
+	Direction string
+}
+
+// WriteMermaidFlowchart writes the graph as a Mermaid flowchart.  Nodes are labelled like WriteDOT and
+// shaped by their AttrType, gateways are rhombuses and events are circles.  Links are labelled with the linkID
+func (g *Graph[K]) WriteMermaidFlowchart(w io.Writer, opts MermaidOptions) error {
+	direction := opts.Direction
+	if direction == "" {
+		direction = "TD"
+	}
+	ids := g.diagramIDs()
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "flowchart %s\n", direction)
+	for _, n := range g.nodesInAddOrder() {
+		open, closed := "[", "]"
+		if nodeType, ok := g.nodes[n].attrs[AttrType].(string); ok {
+			switch {
+			case strings.HasSuffix(nodeType, "Gateway"):
+				open, closed = "{", "}"
+			case strings.HasSuffix(nodeType, "Event"):
+				open, closed = "((", "))"
+			}
+		}
+		fmt.Fprintf(&b, "  %s%s\"%s\"%s\n", ids[n], open, mermaidEscape(g.label(n)), closed)
+	}
+	for _, e := range g.Edges() {
+		if e.LinkID == "" {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[e.From], ids[e.To])
+		} else {
+			fmt.Fprintf(&b, "  %s -->|\"%s\"| %s\n", ids[e.From], mermaidEscape(e.LinkID), ids[e.To])
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WriteMermaidSequence writes the output of TopologicalSort as a Mermaid sequence diagram.  Each node is a
+// participant and each step is a message from the node it was reached from, labelled with the Step and
+// FromLinkID.  Mermaid's autonumber can't follow the Steps so they're part of the message instead.
+// A step without a From node, the start of a root branch, is a note over the node
+func (g *Graph[K]) WriteMermaidSequence(w io.Writer, order []*TopologyOrder[K]) error {
+	ids := g.diagramIDs()
+	var b bytes.Buffer
+	b.WriteString("sequenceDiagram\n")
+	declared := make(map[K]bool, len(order))
+	declare := func(n K) {
+		if !declared[n] {
+			declared[n] = true
+			fmt.Fprintf(&b, "  participant %s as %s\n", ids.id(n), mermaidEscape(g.label(n)))
+		}
+	}
+	for _, to := range order {
+		if to.From != nil {
+			declare(*to.From)
+		}
+		declare(to.Node)
+	}
+	for _, to := range order {
+		text := mermaidEscape(strings.TrimSpace(to.Step + " " + to.FromLinkID))
+		if to.From == nil {
+			fmt.Fprintf(&b, "  Note over %s: %s\n", ids.id(to.Node), text)
+		} else {
+			fmt.Fprintf(&b, "  %s->>%s: %s\n", ids.id(*to.From), ids.id(to.Node), text)
+		}
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// mermaidEscape replaces the characters that would end a Mermaid label with entity codes
+func mermaidEscape(s string) string {
+	return strings.NewReplacer(`"`, "#quot;", ";", "#59;", "\n", "<br/>").Replace(s)
+}
@@ -0,0 +1,47 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestWriteMermaid(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("Flow_1", "start", "approved"))
+	assert.NoError(t, g.AddLink("Flow_2", "approved", "notify"))
+	assert.NoError(t, g.AddLink("Flow_3", "approved", "end"))
+	assert.NoError(t, g.AddLink("Flow_4", "notify", "end"))
+	assert.NoError(t, g.SetNodeAttr("start", depgraph.AttrType, "startEvent"))
+	assert.NoError(t, g.SetNodeAttr("approved", depgraph.AttrType, "exclusiveGateway"))
+	assert.NoError(t, g.SetNodeAttr("approved", depgraph.AttrLabel, `"Approved?"`))
+	assert.NoError(t, g.SetNodeAttr("notify", depgraph.AttrLabel, "Notify; Customer"))
+
+	var b strings.Builder
+	assert.NoError(t, g.WriteMermaidFlowchart(&b, depgraph.MermaidOptions{Direction: "LR"}))
+	assert.Equal(t, `flowchart LR
+  n0(("start"))
+  n1{"#quot;Approved?#quot;"}
+  n2["Notify#59; Customer"]
+  n3["end"]
+  n0 -->|"Flow_1"| n1
+  n1 -->|"Flow_2"| n2
+  n1 -->|"Flow_3"| n3
+  n2 -->|"Flow_4"| n3
+`, b.String())
+
+	b.Reset()
+	assert.NoError(t, g.WriteMermaidSequence(&b, g.TopologicalSort()))
+	assert.Equal(t, `sequenceDiagram
+  participant n0 as start
+  participant n1 as #quot;Approved?#quot;
+  participant n2 as Notify#59; Customer
+  participant n3 as end
+  Note over n0: 1
+  n0->>n1: 2 Flow_1
+  n1->>n2: 3 Flow_2
+  n2->>n3: 4 Flow_4
+`, b.String())
+}
@@ -0,0 +1,66 @@
+package depgraph
+
+import (
+	"cmp"
+	"slices"
+)
+
+// TieBreak is how nodes that are otherwise equal are ordered, whatever the policy nodes that are
+// still equal are in the order they were added so the order is the same every run
+type TieBreak int
+
+const (
+	TieBreakAddOrder TieBreak = iota // The order the nodes were added, the default
+	TieBreakPosition                 // x, then y
+	TieBreakCompare                  // OrderOptions.Compare
+)
+
+// OrderOptions controls the order of the nodes returned by Nodes, Leaves, Roots, Sinks, Dependencies,
+// Dependents, TransitiveDependencies, TransitiveDependents, Edges, Cycles, SortedLayers and Sorted.  The
+// writers, JSON and Execute keep to add order so their output doesn't depend on the options
+type OrderOptions[K comparable] struct {
+	TieBreak TieBreak
+	// Compare is used by TieBreakCompare, it returns < 0 when a comes before b and 0 when they tie
+	Compare func(a, b K) int
+}
+
+// SetOrderOptions sets how ties are broken when ordering nodes
+func (g *Graph[K]) SetOrderOptions(opts OrderOptions[K]) {
+	g.order = opts
+}
+
+// compareNodes orders two nodes using the OrderOptions
+func (g *Graph[K]) compareNodes(a, b K) int {
+	nodeA, nodeB := g.nodes[a], g.nodes[b]
+	switch g.order.TieBreak {
+	case TieBreakPosition:
+		if c := cmp.Compare(nodeA.x, nodeB.x); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(nodeA.y, nodeB.y); c != 0 {
+			return c
+		}
+	case TieBreakCompare:
+		if g.order.Compare != nil {
+			if c := g.order.Compare(a, b); c != 0 {
+				return c
+			}
+		}
+	}
+	return cmp.Compare(nodeA.addOrder, nodeB.addOrder)
+}
+
+// ordered returns the keys of a nodeMap sorted using the OrderOptions
+func (g *Graph[K]) ordered(nm nodeMap[K]) []K {
+	nodes := make([]K, 0, len(nm))
+	for n := range nm {
+		nodes = append(nodes, n)
+	}
+	return g.sortNodes(nodes)
+}
+
+// sortNodes sorts nodes using the OrderOptions
+func (g *Graph[K]) sortNodes(nodes []K) []K {
+	slices.SortFunc(nodes, g.compareNodes)
+	return nodes
+}
@@ -0,0 +1,52 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestOrderOptions(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("b", 20, 0)
+	g.AddNode("C", 10, 5)
+	g.AddNode("a", 10, 0)
+	require.NoError(t, g.DependOn("z", "b"))
+	require.NoError(t, g.DependOn("z", "a"))
+
+	for range 10 {
+		assert.Equal(t, []string{"b", "C", "a"}, g.Leaves())
+		assert.Equal(t, []string{"b", "C", "a", "z"}, g.Nodes())
+		assert.Equal(t, [][]string{{"C", "b", "a"}, {"z"}}, g.SortedLayers())
+	}
+
+	g.SetOrderOptions(depgraph.OrderOptions[string]{TieBreak: depgraph.TieBreakPosition})
+	assert.Equal(t, []string{"a", "C", "b"}, g.Leaves())
+	assert.Equal(t, [][]string{{"C", "a", "b"}, {"z"}}, g.SortedLayers())
+	assert.Equal(t, []string{"z", "a", "C", "b"}, g.Nodes())
+	assert.Equal(t, g.Leaves(), g.Roots())
+	assert.Equal(t, []string{"a", "b"}, g.Dependencies("z"))
+	assert.Equal(t, []string{"a", "b"}, g.TransitiveDependencies("z"))
+	assert.Equal(t, []string{"z", "C"}, g.Sinks())
+	edges := g.Edges()
+	require.Len(t, edges, 2)
+	assert.Equal(t, "a", edges[0].From)
+	cyclic := g.Copy()
+	require.NoError(t, cyclic.DependOn("a", "z"))
+	require.NoError(t, cyclic.DependOn("b", "z"))
+	assert.Equal(t, [][]string{{"z", "a", "b"}}, cyclic.Cycles())
+
+	g.SetOrderOptions(depgraph.OrderOptions[string]{
+		TieBreak: depgraph.TieBreakCompare,
+		Compare: func(a, b string) int {
+			return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+		},
+	})
+	assert.Equal(t, []string{"a", "b", "C"}, g.Leaves())
+	assert.Equal(t, []string{"C", "a", "b", "z"}, g.Sorted())
+	// Options are kept by copies
+	assert.Equal(t, []string{"a", "b", "C", "z"}, g.Copy().Nodes())
+}
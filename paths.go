@@ -0,0 +1,96 @@
+package depgraph
+
+// Path is a route through the graph following parent -> child, LinkIDs[i] is the first link from
+// Nodes[i] to Nodes[i+1], "" when there is no link
+type Path[K comparable] struct {
+	Nodes   []K
+	LinkIDs []string
+}
+
+// ShortestPath returns a route from -> to with the fewest steps, false when to can't be reached from from.
+// Where there are several the one through the nodes added first is returned
+func (g *Graph[K]) ShortestPath(from, to K) (*Path[K], bool) {
+	if _, ok := g.nodes[from]; !ok {
+		return nil, false
+	}
+	if _, ok := g.nodes[to]; !ok {
+		return nil, false
+	}
+	cameFrom := map[K]K{}
+	visited := map[K]bool{from: true}
+	searchNext := []K{from}
+	for len(searchNext) > 0 && !visited[to] {
+		var discovered []K
+		for _, n := range searchNext {
+			for _, child := range g.inAddOrder(g.dependentMap[n]) {
+				if !visited[child] {
+					visited[child] = true
+					cameFrom[child] = n
+					discovered = append(discovered, child)
+				}
+			}
+		}
+		searchNext = discovered
+	}
+	if !visited[to] {
+		return nil, false
+	}
+	nodes := []K{to}
+	for n := to; n != from; {
+		n = cameFrom[n]
+		nodes = append([]K{n}, nodes...)
+	}
+	return g.path(nodes), true
+}
+
+// AllPaths returns every route from -> to that doesn't visit a node twice, stopping after limit paths so
+// large diagrams don't take forever.  A limit of 0 or less returns them all.  Paths through the nodes
+// added first come first
+func (g *Graph[K]) AllPaths(from, to K, limit int) (paths []*Path[K]) {
+	if _, ok := g.nodes[from]; !ok {
+		return nil
+	}
+	// Only nodes that lead to the target are worth following
+	leadsTo := g.dependencies(to)
+	if leadsTo == nil {
+		return nil
+	}
+	leadsTo[to] = g.nodes[to]
+	onPath := make(map[K]bool)
+	var route []K
+	var walk func(n K) bool
+	walk = func(n K) bool {
+		route = append(route, n)
+		onPath[n] = true
+		defer func() {
+			route = route[:len(route)-1]
+			onPath[n] = false
+		}()
+		if n == to {
+			paths = append(paths, g.path(append([]K(nil), route...)))
+			return limit > 0 && len(paths) >= limit
+		}
+		for _, child := range g.inAddOrder(g.dependentMap[n]) {
+			if _, ok := leadsTo[child]; ok && !onPath[child] {
+				if walk(child) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	walk(from)
+	return paths
+}
+
+func (g *Graph[K]) path(nodes []K) *Path[K] {
+	p := &Path[K]{Nodes: nodes, LinkIDs: make([]string, 0, len(nodes))}
+	for i := 1; i < len(nodes); i++ {
+		var linkID string
+		if ids := g.linkIDs(nodes[i-1], nodes[i]); len(ids) > 0 {
+			linkID = ids[0]
+		}
+		p.LinkIDs = append(p.LinkIDs, linkID)
+	}
+	return p
+}
@@ -0,0 +1,70 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestPaths(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	for _, l := range [][3]string{
+		{"1", "Order Submitted", "SIM Type?"},
+		{"2", "SIM Type?", "Prompt for email address"},
+		{"3", "Prompt for email address", "Enter email address"},
+		{"4", "Enter email address", "Capture email address"},
+		{"5", "SIM Type?", "SIM Type Known"},
+		{"6", "Capture email address", "SIM Type Known"},
+		{"7", "SIM Type Known", "Submit & Display Order"},
+		{"9", "Submit & Display Order", "In Parallel"},
+		{"10", "In Parallel", "Require Logistics Order?"},
+		{"12", "Require Logistics Order?", "Fulfil Logistics Order"},
+		{"13", "Require Logistics Order?", "Logistics Handled"},
+		{"14", "Fulfil Logistics Order", "Logistics Handled"},
+		{"15", "Logistics Handled", "Submit CRM Order"},
+	} {
+		require.NoError(t, g.AddLink(l[0], l[1], l[2]))
+	}
+
+	p, ok := g.ShortestPath("Order Submitted", "Submit CRM Order")
+	require.True(t, ok)
+	assert.Equal(t, []string{"Order Submitted", "SIM Type?", "SIM Type Known", "Submit & Display Order", "In Parallel",
+		"Require Logistics Order?", "Logistics Handled", "Submit CRM Order"}, p.Nodes)
+	assert.Equal(t, []string{"1", "5", "7", "9", "10", "13", "15"}, p.LinkIDs)
+
+	p, ok = g.ShortestPath("SIM Type?", "SIM Type?")
+	require.True(t, ok)
+	assert.Equal(t, []string{"SIM Type?"}, p.Nodes)
+	assert.Empty(t, p.LinkIDs)
+
+	_, ok = g.ShortestPath("Submit CRM Order", "Order Submitted")
+	assert.False(t, ok)
+	_, ok = g.ShortestPath("Order Submitted", "Missing")
+	assert.False(t, ok)
+
+	paths := g.AllPaths("Order Submitted", "Submit CRM Order", 0)
+	require.Len(t, paths, 4)
+	linkIDs := make([][]string, len(paths))
+	for i, p := range paths {
+		linkIDs[i] = p.LinkIDs
+		assert.Len(t, p.Nodes, len(p.LinkIDs)+1)
+	}
+	assert.Equal(t, [][]string{
+		{"1", "2", "3", "4", "6", "7", "9", "10", "12", "14", "15"},
+		{"1", "2", "3", "4", "6", "7", "9", "10", "13", "15"},
+		{"1", "5", "7", "9", "10", "12", "14", "15"},
+		{"1", "5", "7", "9", "10", "13", "15"},
+	}, linkIDs)
+
+	assert.Len(t, g.AllPaths("Order Submitted", "Submit CRM Order", 3), 3)
+	assert.Empty(t, g.AllPaths("Submit CRM Order", "Order Submitted", 0))
+
+	// Cycles are not followed round
+	require.NoError(t, g.DependOn("SIM Type?", "Logistics Handled"))
+	assert.Len(t, g.AllPaths("Order Submitted", "Submit CRM Order", 0), 4)
+	paths = g.AllPaths("In Parallel", "SIM Type Known", 0)
+	require.Len(t, paths, 4)
+	assert.Equal(t, []string{"10", "12", "14", "", "2", "3", "4", "6"}, paths[0].LinkIDs)
+}
@@ -0,0 +1,160 @@
+package depgraph
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// PlantUMLOptions controls WritePlantUMLActivity
+type PlantUMLOptions struct {
+	// Fork uses fork blocks, for parallel branches, rather than split blocks
+	Fork bool
+}
+
+// WritePlantUMLActivity writes the output of TopologicalSort as a PlantUML activity diagram.  Each step is
+// an activity labelled with the Step and node label.  Where branches come off a step they, and the rest of
+// the route the step is on, become the arms of a split (or fork) block so the branch structure is kept.
+// The block ends at the step's Join, the route carrying on from there.  Several root branches are arms of
+// a split at the start
+func (g *Graph[K]) WritePlantUMLActivity(w io.Writer, order []*TopologyOrder[K], opts PlantUMLOptions) error {
+	open, again, end := "split", "split again", "end split"
+	if opts.Fork {
+		open, again, end = "fork", "fork again", "end fork"
+	}
+	var b bytes.Buffer
+	var writeSteps func(indent string, steps []*StepNode[K])
+	writeArms := func(indent string, arms [][]*StepNode[K]) {
+		fmt.Fprintf(&b, "%s%s\n", indent, open)
+		for i, arm := range arms {
+			if i > 0 {
+				fmt.Fprintf(&b, "%s%s\n", indent, again)
+			}
+			writeSteps(indent+"  ", arm)
+		}
+		fmt.Fprintf(&b, "%s%s\n", indent, end)
+	}
+	writeSteps = func(indent string, steps []*StepNode[K]) {
+		for i, st := range steps {
+			fmt.Fprintf(&b, "%s:%s %s;\n", indent, st.Order.Step, plantUMLEscape(g.label(st.Order.Node)))
+			if len(st.Branches) == 0 {
+				continue
+			}
+			rest := steps[i+1:]
+			if len(rest) == 0 && len(st.Branches) == 1 {
+				// Nothing to split from, carry straight on down the branch
+				writeSteps(indent, st.Branches[0].Steps)
+				return
+			}
+			// The route carries on after the block from where the branches join
+			var after []*StepNode[K]
+			j := slices.Index(rest, st.Join)
+			if j >= 0 {
+				rest, after = rest[:j], rest[j:]
+			}
+			var arms [][]*StepNode[K]
+			for _, branch := range st.Branches {
+				arms = append(arms, branch.Steps)
+			}
+			// An empty arm when the route goes straight to the join, so the branches stay optional
+			if len(rest) > 0 || j == 0 {
+				arms = append(arms, rest)
+			}
+			writeArms(indent, arms)
+			writeSteps(indent, after)
+			return
+		}
+	}
+
+	b.WriteString("@startuml\nstart\n")
+	roots := g.TopologyTree(order)
+	switch len(roots) {
+	case 0:
+	case 1:
+		writeSteps("", roots[0].Steps)
+	default:
+		arms := make([][]*StepNode[K], len(roots))
+		for i, root := range roots {
+			arms[i] = root.Steps
+		}
+		writeArms("", arms)
+	}
+	b.WriteString("stop\n@enduml\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// WritePlantUMLSequence writes the output of TopologicalSort as a PlantUML sequence diagram.  Each node is a
+// participant and each step a message from the node it was reached from, labelled with the Step and FromLinkID.
+// A branch coming off a step is a group, several branches off the same step are the cases of an alt and
+// root branches other than the first are groups.  A step without a From node is a note over the node
+func (g *Graph[K]) WritePlantUMLSequence(w io.Writer, order []*TopologyOrder[K]) error {
+	ids := g.diagramIDs()
+	var participants, body bytes.Buffer
+	declared := make(map[K]bool, len(order))
+	id := func(n K) string {
+		nodeID := ids.id(n)
+		if !declared[n] {
+			declared[n] = true
+			label := strings.ReplaceAll(plantUMLEscape(g.label(n)), `"`, "'")
+			fmt.Fprintf(&participants, "participant \"%s\" as %s\n", label, nodeID)
+		}
+		return nodeID
+	}
+	var writeSteps func(indent string, steps []*StepNode[K])
+	writeSteps = func(indent string, steps []*StepNode[K]) {
+		for _, st := range steps {
+			to := st.Order
+			text := plantUMLEscape(strings.TrimSpace(to.Step + " " + to.FromLinkID))
+			if to.From == nil {
+				fmt.Fprintf(&body, "%snote over %s : %s\n", indent, id(to.Node), text)
+			} else {
+				from := id(*to.From)
+				fmt.Fprintf(&body, "%s%s -> %s : %s\n", indent, from, id(to.Node), text)
+			}
+			for i, branch := range st.Branches {
+				switch {
+				case len(st.Branches) == 1:
+					fmt.Fprintf(&body, "%sgroup %s\n", indent, branchLabel(branch))
+				case i == 0:
+					fmt.Fprintf(&body, "%salt %s\n", indent, branchLabel(branch))
+				default:
+					fmt.Fprintf(&body, "%selse %s\n", indent, branchLabel(branch))
+				}
+				writeSteps(indent+"  ", branch.Steps)
+			}
+			if len(st.Branches) > 0 {
+				fmt.Fprintf(&body, "%send\n", indent)
+			}
+		}
+	}
+	for i, root := range g.TopologyTree(order) {
+		if i == 0 {
+			writeSteps("", root.Steps)
+			continue
+		}
+		fmt.Fprintf(&body, "group %s\n", branchLabel(root))
+		writeSteps("  ", root.Steps)
+		body.WriteString("end\n")
+	}
+
+	var b bytes.Buffer
+	b.WriteString("@startuml\n")
+	b.Write(participants.Bytes())
+	b.Write(body.Bytes())
+	b.WriteString("@enduml\n")
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// branchLabel names the branch by its BranchStep, e.g. 7.1 is on branch 7
+func branchLabel[K comparable](b *StepBranch[K]) string {
+	return "branch " + b.Steps[0].Order.BranchStep
+}
+
+// plantUMLEscape puts newlines as \n so labels stay on one line
+func plantUMLEscape(s string) string {
+	return strings.ReplaceAll(s, "\n", `\n`)
+}
@@ -0,0 +1,172 @@
+package depgraph_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func plantUMLGraph(t *testing.T) *depgraph.Graph[string] {
+	g := depgraph.NewGraph[string]()
+	// Positions break the ties between branches with the same number of dependents, so the sort is the
+	// same every run
+	for i, n := range []string{"start", "approved?", "fulfil", "ship", "end", "reject", "escalate", "invoice", "cancel", "refund"} {
+		g.AddNode(n, float32(i*10), 0)
+	}
+	assert.NoError(t, g.AddLink("1", "start", "approved?"))
+	assert.NoError(t, g.AddLink("2", "approved?", "fulfil"))
+	assert.NoError(t, g.AddLink("3", "fulfil", "ship"))
+	assert.NoError(t, g.AddLink("4", "ship", "end"))
+	assert.NoError(t, g.AddLink("5", "approved?", "reject"))
+	assert.NoError(t, g.AddLink("6", "approved?", "escalate"))
+	assert.NoError(t, g.AddLink("7", "fulfil", "invoice"))
+	assert.NoError(t, g.AddLink("8", "cancel", "refund"))
+	return g
+}
+
+func TestWritePlantUMLActivity(t *testing.T) {
+	g := plantUMLGraph(t)
+	var b strings.Builder
+	assert.NoError(t, g.WritePlantUMLActivity(&b, g.TopologicalSort(), depgraph.PlantUMLOptions{}))
+	assert.Equal(t, `@startuml
+start
+split
+  :1 start;
+  :2 approved?;
+  split
+    :2.1.1 reject;
+  split again
+    :2.2.1 escalate;
+  split again
+    :3 fulfil;
+    split
+      :3.1 invoice;
+    split again
+      :4 ship;
+      :5 end;
+    end split
+  end split
+split again
+  :A.1 cancel;
+  :A.2 refund;
+end split
+stop
+@enduml
+`, b.String())
+
+	b.Reset()
+	assert.NoError(t, g.WritePlantUMLActivity(&b, g.TopologicalSort(), depgraph.PlantUMLOptions{Fork: true}))
+	assert.Contains(t, b.String(), "fork again")
+}
+
+func TestWritePlantUMLActivityJoin(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	for _, l := range [][3]string{
+		{"1", "start", "split"},
+		{"2", "split", "a"},
+		{"3", "a", "b"},
+		{"4", "b", "join"},
+		{"5", "split", "c"},
+		{"6", "c", "join"},
+		{"7", "join", "end"},
+	} {
+		assert.NoError(t, g.AddLink(l[0], l[1], l[2]))
+	}
+	var b strings.Builder
+	assert.NoError(t, g.WritePlantUMLActivity(&b, g.TopologicalSort(), depgraph.PlantUMLOptions{}))
+	assert.Equal(t, `@startuml
+start
+:1 start;
+:2 split;
+split
+  :2.1 c;
+split again
+  :3 a;
+  :4 b;
+end split
+:5 join;
+:6 end;
+stop
+@enduml
+`, b.String())
+}
+
+func TestWritePlantUMLSequence(t *testing.T) {
+	g := plantUMLGraph(t)
+	var b strings.Builder
+	assert.NoError(t, g.WritePlantUMLSequence(&b, g.TopologicalSort()))
+	assert.Equal(t, `@startuml
+participant "start" as n0
+participant "approved?" as n1
+participant "reject" as n5
+participant "escalate" as n6
+participant "fulfil" as n2
+participant "invoice" as n7
+participant "ship" as n3
+participant "end" as n4
+participant "cancel" as n8
+participant "refund" as n9
+note over n0 : 1
+n0 -> n1 : 2 1
+alt branch 2.1
+  n1 -> n5 : 2.1.1 5
+else branch 2.2
+  n1 -> n6 : 2.2.1 6
+end
+n1 -> n2 : 3 2
+group branch 3
+  n2 -> n7 : 3.1 7
+end
+n2 -> n3 : 4 3
+n3 -> n4 : 5 4
+group branch A
+  note over n8 : A.1
+  n8 -> n9 : A.2 8
+end
+@enduml
+`, b.String())
+}
+
+func TestWritePlantUMLSequenceStepFormat(t *testing.T) {
+	g := plantUMLGraph(t)
+	order := g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{Steps: depgraph.StepOptions{
+		Formatter: depgraph.StepFormat{Separator: "-", Styles: []depgraph.NumberStyle{depgraph.Decimal, depgraph.LowerRoman}},
+	}})
+	var b strings.Builder
+	assert.NoError(t, g.WritePlantUMLSequence(&b, order))
+	assert.Contains(t, b.String(), "alt branch 2-i\n  n1 -> n5 : 2-i-i 5\nelse branch 2-ii\n")
+	assert.Contains(t, b.String(), "group branch 3\n  n2 -> n7 : 3-i 7\n")
+	assert.Contains(t, b.String(), "group branch A\n  note over n8 : A-1\n")
+}
+
+func TestWritePlantUMLActivityStraightToJoin(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("1", "start", "S"))
+	assert.NoError(t, g.AddLink("2", "S", "b"))
+	assert.NoError(t, g.AddLink("3", "b", "J"))
+	assert.NoError(t, g.AddLink("4", "S", "J"))
+	assert.NoError(t, g.AddLink("5", "J", "end"))
+	// The shortest branch is the main route, so it goes straight from S to J
+	order := g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{
+		Strategy: depgraph.BranchStrategyFunc[string](func(a, b *depgraph.Branch[string]) int {
+			return a.Dependents - b.Dependents
+		}),
+	})
+	var b strings.Builder
+	assert.NoError(t, g.WritePlantUMLActivity(&b, order, depgraph.PlantUMLOptions{}))
+	assert.Equal(t, `@startuml
+start
+:1 start;
+:2 S;
+split
+  :2.1 b;
+split again
+end split
+:3 J;
+:4 end;
+stop
+@enduml
+`, b.String())
+}
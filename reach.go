@@ -0,0 +1,122 @@
+package depgraph
+
+// reachIndex is the transitive closure of the graph as a bitset per node of the nodes depending on it
+type reachIndex[K comparable] struct {
+	index map[K]int
+	reach []bitset
+}
+
+type bitset []uint64
+
+func (b bitset) has(i int) bool {
+	return i/64 < len(b) && b[i/64]&(1<<(i%64)) != 0
+}
+
+func (b *bitset) set(i int) {
+	for len(*b) <= i/64 {
+		*b = append(*b, 0)
+	}
+	(*b)[i/64] |= 1 << (i % 64)
+}
+
+func (b *bitset) and(o bitset) {
+	if len(*b) > len(o) {
+		*b = (*b)[:len(o)]
+	}
+	for i, w := range o[:len(*b)] {
+		(*b)[i] &= w
+	}
+}
+
+func (b *bitset) or(o bitset) {
+	for len(*b) < len(o) {
+		*b = append(*b, 0)
+	}
+	for i, w := range o {
+		(*b)[i] |= w
+	}
+}
+
+// BuildReachabilityIndex precomputes which nodes depend on which so DependsOn and HasDependent are a
+// lookup rather than a search.  The index is kept up to date by DependOn and AddLink, removing a node or
+// link drops it and it has to be built again.  Building the index changes the graph, so don't build it on
+// a SyncGraph snapshot
+func (g *Graph[K]) BuildReachabilityIndex() {
+	g.reach = g.reachabilityIndex()
+}
+
+// reachabilityIndex builds the index without keeping it
+func (g *Graph[K]) reachabilityIndex() *reachIndex[K] {
+	ri := &reachIndex[K]{index: make(map[K]int, len(g.nodes))}
+	nodes := g.nodesInAddOrder()
+	for i, n := range nodes {
+		ri.index[n] = i
+	}
+	ri.reach = make([]bitset, len(nodes))
+	if sorted, err := g.SortedStrict(); err == nil {
+		// Children before parents, each node reaches its children and what they reach
+		for i := len(sorted) - 1; i >= 0; i-- {
+			r := &ri.reach[ri.index[sorted[i]]]
+			for child := range g.dependentMap[sorted[i]] {
+				r.set(ri.index[child])
+				r.or(ri.reach[ri.index[child]])
+			}
+		}
+	} else {
+		for i, n := range nodes {
+			for dependent := range g.dependents(n) {
+				ri.reach[i].set(ri.index[dependent])
+			}
+		}
+	}
+	return ri
+}
+
+// DropReachabilityIndex removes the index built by BuildReachabilityIndex
+func (g *Graph[K]) DropReachabilityIndex() {
+	g.reach = nil
+}
+
+// reaches returns true if child depends on parent according to the index
+func (ri *reachIndex[K]) reaches(parent, child K) bool {
+	p, ok := ri.index[parent]
+	if !ok {
+		return false
+	}
+	c, ok := ri.index[child]
+	return ok && ri.reach[p].has(c)
+}
+
+// dependOn adds parent -> child to the index, parent and everything it depends on now reach child
+// and everything child reaches
+func (ri *reachIndex[K]) dependOn(child, parent K) {
+	for _, n := range []K{parent, child} {
+		if _, ok := ri.index[n]; !ok {
+			ri.index[n] = len(ri.reach)
+			ri.reach = append(ri.reach, nil)
+		}
+	}
+	p, c := ri.index[parent], ri.index[child]
+	var added bitset
+	added.set(c)
+	added.or(ri.reach[c])
+	for i := range ri.reach {
+		if i == p || ri.reach[i].has(p) {
+			ri.reach[i].or(added)
+		}
+	}
+}
+
+func (ri *reachIndex[K]) copy() *reachIndex[K] {
+	if ri == nil {
+		return nil
+	}
+	c := &reachIndex[K]{index: make(map[K]int, len(ri.index)), reach: make([]bitset, len(ri.reach))}
+	for n, i := range ri.index {
+		c.index[n] = i
+	}
+	for i, r := range ri.reach {
+		c.reach[i] = append(bitset(nil), r...)
+	}
+	return c
+}
@@ -0,0 +1,54 @@
+package depgraph_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+	"github.com/timdadd/depgraph/bpmn"
+)
+
+// assertSameReach checks the indexed graph answers DependsOn and HasDependent the same as a graph without
+func assertSameReach(t *testing.T, indexed *depgraph.Graph[string]) {
+	plain := indexed.Copy()
+	plain.DropReachabilityIndex()
+	nodes := plain.Nodes()
+	for _, a := range nodes {
+		for _, b := range nodes {
+			require.Equal(t, plain.DependsOn(a, b), indexed.DependsOn(a, b), "%v depends on %v", a, b)
+			require.Equal(t, plain.HasDependent(a, b), indexed.HasDependent(a, b), "%v has dependent %v", a, b)
+		}
+	}
+}
+
+func TestReachabilityIndex(t *testing.T) {
+	f, err := os.Open("bpmn/TestTopologicalSort005.xml")
+	require.NoError(t, err)
+	defer f.Close()
+	g, err := bpmn.Load(f)
+	require.NoError(t, err)
+
+	g.BuildReachabilityIndex()
+	assertSameReach(t, g)
+
+	// Kept up to date as links are added, including new nodes and a cycle
+	require.NoError(t, g.AddLink("new", "Event_1b30bns", "Extra"))
+	require.NoError(t, g.DependOn("Extra2", "Extra"))
+	assert.True(t, g.DependsOn("Extra2", "Event_1b30bns"))
+	assertSameReach(t, g)
+	edges := g.Edges()
+	require.NoError(t, g.DependOn(edges[0].From, edges[len(edges)-1].To))
+	assertSameReach(t, g)
+
+	// Dropped when something is removed
+	require.NoError(t, g.RemoveNode("Extra"))
+	assert.False(t, g.DependsOn("Extra2", "Event_1b30bns"))
+	assertSameReach(t, g)
+
+	// Built on a graph with cycles
+	g.BuildReachabilityIndex()
+	assertSameReach(t, g)
+	assert.False(t, g.DependsOn("Missing", "Extra2"))
+}
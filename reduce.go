@@ -0,0 +1,44 @@
+package depgraph
+
+// TransitiveReduction returns a copy of the graph without the redundant edges, those returned by
+// RedundantEdges, so every node still depends on the same nodes through fewer edges
+func (g *Graph[K]) TransitiveReduction() *Graph[K] {
+	reduced, _ := g.reduce()
+	return reduced
+}
+
+// RedundantEdges returns the edges that are implied by other edges, e.g. web -> database is redundant
+// when there is also web -> aggregator -> database.  Edges are checked in Edges order and each one found
+// is dropped before checking the rest, so with cycles only edges that can all be removed together are returned
+func (g *Graph[K]) RedundantEdges() []Edge[K] {
+	_, redundant := g.reduce()
+	return redundant
+}
+
+func (g *Graph[K]) reduce() (reduced *Graph[K], redundant []Edge[K]) {
+	reduced = g.Copy()
+	for _, from := range g.nodesInAddOrder() {
+		for _, to := range g.inAddOrder(g.dependentMap[from]) {
+			if !reduced.reachableWithout(from, to) {
+				continue
+			}
+			redundant = append(redundant, g.edges(from, to)...)
+			_ = reduced.RemoveLink(from, to)
+		}
+	}
+	return reduced, redundant
+}
+
+// reachableWithout returns true if to depends on from without the edge from -> to
+func (g *Graph[K]) reachableWithout(from, to K) bool {
+	reachable := g.buildTransitive(from, func(n K) nodeMap[K] {
+		if n != from {
+			return g.immediateDependents(n)
+		}
+		children := copyNodeset(g.immediateDependents(n))
+		delete(children, to)
+		return children
+	})
+	_, ok := reachable[to]
+	return ok
+}
@@ -0,0 +1,52 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestTransitiveReduction(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.DependOn("web", "database"))
+	require.NoError(t, g.AddLink("agg", "database", "aggregator"))
+	require.NoError(t, g.DependOn("web", "aggregator"))
+	require.NoError(t, g.DependOn("web", "logger"))
+	require.NoError(t, g.DependOn("web", "config"))
+	require.NoError(t, g.DependOn("web", "metrics"))
+	require.NoError(t, g.DependOn("database", "config"))
+	require.NoError(t, g.DependOn("metrics", "config"))
+
+	assert.Equal(t, []depgraph.Edge[string]{
+		{From: "database", To: "web"},
+		{From: "config", To: "web"},
+	}, g.RedundantEdges())
+
+	reduced := g.TransitiveReduction()
+	assert.Len(t, reduced.Edges(), len(g.Edges())-2)
+	assert.NotContains(t, reduced.Edges(), depgraph.Edge[string]{From: "database", To: "web"})
+	assert.Equal(t, []string{"agg"}, reduced.LinkIDs("database", "aggregator"))
+	for _, parent := range []string{"database", "aggregator", "logger", "config", "metrics"} {
+		assert.True(t, reduced.DependsOn("web", parent), parent)
+	}
+	// The original is unchanged
+	assert.Contains(t, g.Edges(), depgraph.Edge[string]{From: "database", To: "web"})
+	assert.Empty(t, reduced.RedundantEdges())
+}
+
+func TestTransitiveReductionCycle(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.AddLink("ab", "a", "b"))
+	require.NoError(t, g.AddLink("ba", "b", "a"))
+	require.NoError(t, g.AddLink("ac", "a", "c"))
+	require.NoError(t, g.AddLink("bc", "b", "c"))
+
+	redundant := g.RedundantEdges()
+	require.Len(t, redundant, 1)
+	assert.Equal(t, "ac", redundant[0].LinkID)
+	reduced := g.TransitiveReduction()
+	assert.True(t, reduced.DependsOn("c", "a"))
+	assert.True(t, reduced.DependsOn("c", "b"))
+}
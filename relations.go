@@ -0,0 +1,51 @@
+package depgraph
+
+// Dependencies returns the nodes id depends on directly, ordered by the OrderOptions
+func (g *Graph[K]) Dependencies(id K) []K {
+	return g.ordered(g.immediateDependencies(id))
+}
+
+// Dependents returns the nodes that depend on id directly, ordered by the OrderOptions
+func (g *Graph[K]) Dependents(id K) []K {
+	return g.ordered(g.immediateDependents(id))
+}
+
+// TransitiveDependencies returns every node id depends on, directly or not, ordered by the OrderOptions
+func (g *Graph[K]) TransitiveDependencies(id K) []K {
+	return g.ordered(g.dependencies(id))
+}
+
+// TransitiveDependents returns every node that depends on id, directly or not, ordered by the OrderOptions
+func (g *Graph[K]) TransitiveDependents(id K) []K {
+	return g.ordered(g.dependents(id))
+}
+
+// InDegree is the number of nodes id depends on directly, several links between two nodes count once
+func (g *Graph[K]) InDegree(id K) int {
+	return len(g.dependencyMap[id])
+}
+
+// OutDegree is the number of nodes that depend on id directly, several links between two nodes count once
+func (g *Graph[K]) OutDegree(id K) int {
+	return len(g.dependentMap[id])
+}
+
+// Roots returns the nodes that don't depend on anything, ordered by the OrderOptions
+func (g *Graph[K]) Roots() (roots []K) {
+	for _, n := range g.Nodes() {
+		if g.InDegree(n) == 0 {
+			roots = append(roots, n)
+		}
+	}
+	return roots
+}
+
+// Sinks returns the nodes that nothing depends on, ordered by the OrderOptions
+func (g *Graph[K]) Sinks() (sinks []K) {
+	for _, n := range g.Nodes() {
+		if g.OutDegree(n) == 0 {
+			sinks = append(sinks, n)
+		}
+	}
+	return sinks
+}
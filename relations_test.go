@@ -0,0 +1,39 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestRelations(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	require.NoError(t, g.DependOn("web", "database"))
+	require.NoError(t, g.DependOn("web", "aggregator"))
+	require.NoError(t, g.DependOn("aggregator", "database"))
+	require.NoError(t, g.DependOn("web", "logger"))
+	require.NoError(t, g.DependOn("web", "config"))
+	require.NoError(t, g.DependOn("web", "metrics"))
+	require.NoError(t, g.DependOn("database", "config"))
+	require.NoError(t, g.DependOn("metrics", "config"))
+	require.NoError(t, g.AddLink("1", "metrics", "dashboard"))
+	require.NoError(t, g.AddLink("2", "metrics", "dashboard"))
+
+	assert.Equal(t, []string{"database", "aggregator", "logger", "config", "metrics"}, g.Dependencies("web"))
+	assert.Equal(t, []string{"database", "web", "metrics"}, g.Dependents("config"))
+	assert.Equal(t, []string{"config", "metrics"}, g.TransitiveDependencies("dashboard"))
+	assert.Equal(t, []string{"database", "aggregator", "logger", "config", "metrics"}, g.TransitiveDependencies("web"))
+	assert.Equal(t, []string{"database", "web", "aggregator", "metrics", "dashboard"}, g.TransitiveDependents("config"))
+	assert.Empty(t, g.Dependencies("config"))
+	assert.Empty(t, g.TransitiveDependents("Missing"))
+
+	assert.Equal(t, 5, g.InDegree("web"))
+	assert.Equal(t, 0, g.OutDegree("web"))
+	assert.Equal(t, 1, g.InDegree("dashboard"))
+	assert.Equal(t, 2, g.OutDegree("metrics"))
+
+	assert.Equal(t, []string{"logger", "config"}, g.Roots())
+	assert.Equal(t, []string{"web", "dashboard"}, g.Sinks())
+}
@@ -0,0 +1,134 @@
+package depgraph
+
+import (
+	"strconv"
+	"strings"
+)
+
+// StepPart is one number of a Step, e.g. 7.2.1 has three parts.  Root parts label the root branches
+// other than the first, "A" in the default A.1
+type StepPart struct {
+	Number int // From 1
+	Root   bool
+}
+
+// StepFormatter turns the parts of a step into the TopologyOrder.Step
+type StepFormatter interface {
+	FormatStep(parts []StepPart) string
+}
+
+// StepFormatterFunc lets a function be used as a StepFormatter
+type StepFormatterFunc func(parts []StepPart) string
+
+func (f StepFormatterFunc) FormatStep(parts []StepPart) string {
+	return f(parts)
+}
+
+// StepOptions controls the numbering of TopologicalSortWithOptions
+type StepOptions struct {
+	// Formatter formats the Step, nil is DefaultStepFormat
+	Formatter StepFormatter
+	// SortedPadding is the width numbers are zero padded to in the SortedStep, 0 is 4
+	SortedPadding int
+	// FullBranchNumbers numbers the second of two branches off a step like three or more, 1.1.1 rather
+	// than 1.1
+	FullBranchNumbers bool
+}
+
+// NumberStyle is how StepFormat writes a number
+type NumberStyle int
+
+const (
+	DefaultStyle NumberStyle = iota // Decimal for the numbers of a step, UpperLetter for a root branch
+	Decimal                         // 1, 2, 3
+	UpperLetter                     // A, B ... Z, AA
+	LowerLetter                     // a, b ... z, aa
+	UpperRoman                      // I, II, III
+	LowerRoman                      // i, ii, iii
+)
+
+// StepFormat is a StepFormatter for the common styles, e.g. 1.2.3, 1-2-3, I.II.III or the outline 1.a.i
+type StepFormat struct {
+	// Separator goes between the parts, "" is "."
+	Separator string
+	// Width zero pads Decimal numbers
+	Width int
+	// Styles is the style of each part after any root part, the last style is used for deeper parts.
+	// None is Decimal
+	Styles []NumberStyle
+	// RootStyle is the style of the root branch part, DefaultStyle is UpperLetter.  Use a different style
+	// to the first of Styles so the steps of a root branch can't be the same as those on the main route
+	RootStyle NumberStyle
+}
+
+// DefaultStepFormat is the numbering used by TopologicalSort, e.g. 1, 7.2.1 and A.1
+var DefaultStepFormat = StepFormat{RootStyle: UpperLetter}
+
+func (f StepFormat) FormatStep(parts []StepPart) string {
+	separator := f.Separator
+	if separator == "" {
+		separator = "."
+	}
+	text := make([]string, len(parts))
+	depth := 0
+	for i, p := range parts {
+		if p.Root {
+			style := f.RootStyle
+			if style == DefaultStyle {
+				style = UpperLetter
+			}
+			text[i] = f.formatNumber(p.Number, style)
+			continue
+		}
+		style := Decimal
+		if len(f.Styles) > 0 {
+			style = f.Styles[min(depth, len(f.Styles)-1)]
+		}
+		text[i] = f.formatNumber(p.Number, style)
+		depth++
+	}
+	return strings.Join(text, separator)
+}
+
+func (f StepFormat) formatNumber(n int, style NumberStyle) string {
+	switch style {
+	case UpperLetter:
+		return letters(n)
+	case LowerLetter:
+		return strings.ToLower(letters(n))
+	case UpperRoman:
+		return roman(n)
+	case LowerRoman:
+		return strings.ToLower(roman(n))
+	}
+	s := strconv.Itoa(n)
+	if len(s) < f.Width {
+		s = strings.Repeat("0", f.Width-len(s)) + s
+	}
+	return s
+}
+
+// letters numbers like spreadsheet columns, A to Z then AA
+func letters(n int) (s string) {
+	for ; n > 0; n = (n - 1) / 26 {
+		s = string(rune('A'+(n-1)%26)) + s
+	}
+	return s
+}
+
+func roman(n int) string {
+	numerals := []struct {
+		value  int
+		symbol string
+	}{
+		{1000, "M"}, {900, "CM"}, {500, "D"}, {400, "CD"}, {100, "C"}, {90, "XC"},
+		{50, "L"}, {40, "XL"}, {10, "X"}, {9, "IX"}, {5, "V"}, {4, "IV"}, {1, "I"},
+	}
+	var b strings.Builder
+	for _, numeral := range numerals {
+		for ; n >= numeral.value; n -= numeral.value {
+			b.WriteString(numeral.symbol)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,88 @@
+package depgraph_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestStepOptions(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("r1", 0, 0)
+	g.AddNode("r2", 10, 0)
+	for _, l := range [][2]string{{"r1", "a"}, {"r1", "b"}, {"r1", "c"}, {"a", "d"}, {"a", "e"}, {"d", "f"}, {"e", "f2"}, {"r2", "z"}} {
+		require.NoError(t, g.DependOn(l[1], l[0]))
+	}
+	steps := func(opts depgraph.StepOptions) (steps []string) {
+		for _, to := range g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{Steps: opts}) {
+			steps = append(steps, to.Node+" "+to.Step+" "+to.SortedStep)
+		}
+		return steps
+	}
+
+	assert.Equal(t, []string{
+		"r1 1 0001", "b 1.1.1 0001.0001.0001", "c 1.2.1 0001.0002.0001", "a 2 0002", "e 2.1 0002.0001",
+		"f2 2.2 0002.0002", "d 3 0003", "f 4 0004", "r2 A.1 A.0001", "z A.2 A.0002",
+	}, steps(depgraph.StepOptions{}))
+
+	assert.Equal(t, []string{
+		"r1 01 01", "b 01-01-01 01.01.01", "c 01-02-01 01.02.01", "a 02 02", "e 02-01-01 02.01.01",
+		"f2 02-01-02 02.01.02", "d 03 03", "f 04 04", "r2 A-01 A.01", "z A-02 A.02",
+	}, steps(depgraph.StepOptions{
+		Formatter:         depgraph.StepFormat{Separator: "-", Width: 2, RootStyle: depgraph.UpperLetter},
+		SortedPadding:     2,
+		FullBranchNumbers: true,
+	}))
+
+	assert.Equal(t, []string{
+		"r1 1 0001", "b 1.a.i 0001.0001.0001", "c 1.b.i 0001.0002.0001", "a 2 0002", "e 2.a 0002.0001",
+		"f2 2.b 0002.0002", "d 3 0003", "f 4 0004", "r2 I.1 A.0001", "z I.2 A.0002",
+	}, steps(depgraph.StepOptions{Formatter: depgraph.StepFormat{
+		Styles:    []depgraph.NumberStyle{depgraph.Decimal, depgraph.LowerLetter, depgraph.LowerRoman},
+		RootStyle: depgraph.UpperRoman,
+	}}))
+
+	assert.Equal(t, "r1 #1 0001", steps(depgraph.StepOptions{
+		Formatter: depgraph.StepFormatterFunc(func(parts []depgraph.StepPart) string {
+			return fmt.Sprintf("#%d", len(parts))
+		}),
+	})[0])
+}
+
+func TestStepsAreUnique(t *testing.T) {
+	// Root branches are lettered unless told otherwise so no two steps are the same, c is 1.1 and r2 A.1
+	g := depgraph.NewGraph[string]()
+	g.AddNode("r1", 0, 0)
+	g.AddNode("r2", 10, 0)
+	for _, l := range [][2]string{{"r1", "a"}, {"a", "b"}, {"r1", "c"}, {"r2", "z"}} {
+		require.NoError(t, g.DependOn(l[1], l[0]))
+	}
+	for _, format := range []depgraph.StepFormat{
+		{},
+		{Separator: "-"},
+		{Styles: []depgraph.NumberStyle{depgraph.Decimal, depgraph.LowerRoman}},
+	} {
+		seen := make(map[string]string)
+		for _, to := range g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{
+			Steps: depgraph.StepOptions{Formatter: format},
+		}) {
+			assert.NotContains(t, seen, to.Step, "%s and %s", seen[to.Step], to.Node)
+			seen[to.Step] = to.Node
+		}
+	}
+}
+
+func TestStepFormat(t *testing.T) {
+	parts := []depgraph.StepPart{{Number: 28, Root: true}, {Number: 1994}, {Number: 4}}
+	assert.Equal(t, "AB.1994.4", depgraph.DefaultStepFormat.FormatStep(parts))
+	assert.Equal(t, "28/MCMXCIV/iv", depgraph.StepFormat{
+		Separator: "/",
+		Styles:    []depgraph.NumberStyle{depgraph.UpperRoman, depgraph.LowerRoman},
+		RootStyle: depgraph.Decimal,
+	}.FormatStep(parts))
+	assert.Equal(t, "ab", depgraph.StepFormat{RootStyle: depgraph.LowerLetter}.FormatStep(parts[:1]))
+	assert.Equal(t, "00004", depgraph.StepFormat{Width: 5}.FormatStep(parts[2:]))
+}
@@ -0,0 +1,67 @@
+package depgraph
+
+import (
+	"cmp"
+)
+
+// Branch is a node TopologicalSort could go to next, given to a BranchStrategy to put in order
+type Branch[K comparable] struct {
+	Node K
+	// From is the node the branch comes off, nil for the nodes the sort starts from
+	From *K
+	// Root is true for the nodes the sort starts from, those without dependencies
+	Root bool
+	// Dependents is the number of nodes not yet sorted that depend on Node
+	Dependents int
+	X, Y       float32
+	AddOrder   int
+	Attributes Attributes
+	// LinkAttributes are those of the first link From -> Node
+	LinkAttributes Attributes
+}
+
+// BranchStrategy decides the order TopologicalSort follows the branches from a node, the first branch is
+// the main route and the rest are numbered off it.  Branches that Compare as equal are taken in add order
+type BranchStrategy[K comparable] interface {
+	// Compare returns < 0 when a should be followed before b
+	Compare(a, b *Branch[K]) int
+}
+
+// BranchStrategyFunc lets a function be used as a BranchStrategy
+type BranchStrategyFunc[K comparable] func(a, b *Branch[K]) int
+
+func (f BranchStrategyFunc[K]) Compare(a, b *Branch[K]) int {
+	return f(a, b)
+}
+
+// DefaultBranchStrategy is the strategy used by TopologicalSort.  The branch with the most dependents, the
+// longest, is the main route with ties going to the one furthest left and then furthest up.  The root
+// nodes are ordered by position only so the sort starts top left
+type DefaultBranchStrategy[K comparable] struct {
+	// TopToBottom compares y before x, for diagrams that flow down the page
+	TopToBottom bool
+}
+
+func (s DefaultBranchStrategy[K]) Compare(a, b *Branch[K]) int {
+	if !a.Root {
+		if c := cmp.Compare(b.Dependents, a.Dependents); c != 0 {
+			return c
+		}
+	}
+	first, second := cmp.Compare(a.X, b.X), cmp.Compare(a.Y, b.Y)
+	if s.TopToBottom {
+		first, second = second, first
+	}
+	if first != 0 {
+		return first
+	}
+	return second
+}
+
+// TopologicalSortOptions controls TopologicalSortWithOptions
+type TopologicalSortOptions[K comparable] struct {
+	// Strategy orders the branches, nil is the DefaultBranchStrategy
+	Strategy BranchStrategy[K]
+	// Steps controls how the steps are numbered
+	Steps StepOptions
+}
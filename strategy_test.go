@@ -0,0 +1,54 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func TestTopologicalSortWithOptions(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	g.AddNode("start", 0, 0)
+	g.AddNode("left", 10, 50)
+	g.AddNode("top", 50, 10)
+	require.NoError(t, g.AddLink("1", "start", "left"))
+	require.NoError(t, g.AddLink("2", "start", "top"))
+	require.NoError(t, g.SetLinkAttrByID("2", depgraph.AttrLabel, "Yes"))
+
+	steps := func(order []*depgraph.TopologyOrder[string]) map[string]string {
+		s := make(map[string]string, len(order))
+		for _, to := range order {
+			s[to.Node] = to.Step
+		}
+		return s
+	}
+
+	assert.Equal(t, map[string]string{"start": "1", "left": "2", "top": "1.1"}, steps(g.TopologicalSort()))
+	assert.Equal(t, g.TopologicalSort(), g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{}))
+
+	order := g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{
+		Strategy: depgraph.DefaultBranchStrategy[string]{TopToBottom: true},
+	})
+	assert.Equal(t, map[string]string{"start": "1", "top": "2", "left": "1.1"}, steps(order))
+
+	// Follow the "Yes" flow first
+	var seen []*depgraph.Branch[string]
+	order = g.TopologicalSortWithOptions(depgraph.TopologicalSortOptions[string]{
+		Strategy: depgraph.BranchStrategyFunc[string](func(a, b *depgraph.Branch[string]) int {
+			seen = append(seen, a, b)
+			switch {
+			case a.LinkAttributes[depgraph.AttrLabel] == "Yes":
+				return -1
+			case b.LinkAttributes[depgraph.AttrLabel] == "Yes":
+				return 1
+			}
+			return 0
+		}),
+	})
+	assert.Equal(t, map[string]string{"start": "1", "top": "2", "left": "1.1"}, steps(order))
+	require.NotEmpty(t, seen)
+	assert.Equal(t, "start", *seen[0].From)
+	assert.False(t, seen[0].Root)
+}
@@ -0,0 +1,149 @@
+package depgraph
+
+import (
+	"maps"
+	"sync"
+	"sync/atomic"
+)
+
+// SyncGraph is a Graph that is safe for concurrent use.  It is copy-on-write, each update is made to a
+// copy of the graph which then replaces the current one, so readers work on a consistent snapshot and
+// never block writers.  Writers are serialised, use Update to make several changes with one copy
+type SyncGraph[K comparable] struct {
+	mu      sync.Mutex // Held by writers
+	current atomic.Pointer[Graph[K]]
+}
+
+// NewSyncGraph returns an empty SyncGraph
+func NewSyncGraph[K comparable]() *SyncGraph[K] {
+	return NewSyncGraphFrom(NewGraph[K]())
+}
+
+// NewSyncGraphFrom returns a SyncGraph starting with a copy of g
+func NewSyncGraphFrom[K comparable](g *Graph[K]) *SyncGraph[K] {
+	s := &SyncGraph[K]{}
+	s.current.Store(g.Copy())
+	return s
+}
+
+// Snapshot returns the current graph, it must not be changed but can be read for as long as needed
+func (s *SyncGraph[K]) Snapshot() *Graph[K] {
+	return s.current.Load()
+}
+
+// Update calls fn with a copy of the current graph and, if fn returns nil, makes the copy current
+func (s *SyncGraph[K]) Update(fn func(g *Graph[K]) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	g := s.current.Load().Copy()
+	if err := fn(g); err != nil {
+		return err
+	}
+	s.current.Store(g)
+	return nil
+}
+
+// AddNode is Graph.AddNode on a new snapshot
+func (s *SyncGraph[K]) AddNode(id K, x, y float32) {
+	_ = s.Update(func(g *Graph[K]) error {
+		g.AddNode(id, x, y)
+		return nil
+	})
+}
+
+// AddLink is Graph.AddLink on a new snapshot
+func (s *SyncGraph[K]) AddLink(linkID string, from, to K) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.AddLink(linkID, from, to)
+	})
+}
+
+// DependOn is Graph.DependOn on a new snapshot
+func (s *SyncGraph[K]) DependOn(child, parent K) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.DependOn(child, parent)
+	})
+}
+
+// RemoveNode is Graph.RemoveNode on a new snapshot
+func (s *SyncGraph[K]) RemoveNode(nodeID K) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.RemoveNode(nodeID)
+	})
+}
+
+// RemoveLink is Graph.RemoveLink on a new snapshot
+func (s *SyncGraph[K]) RemoveLink(from, to K) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.RemoveLink(from, to)
+	})
+}
+
+// SetNodeAttr is Graph.SetNodeAttr on a new snapshot
+func (s *SyncGraph[K]) SetNodeAttr(id K, key string, value any) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.SetNodeAttr(id, key, value)
+	})
+}
+
+// SetLinkAttrByID is Graph.SetLinkAttrByID on a new snapshot
+func (s *SyncGraph[K]) SetLinkAttrByID(linkID string, key string, value any) error {
+	return s.Update(func(g *Graph[K]) error {
+		return g.SetLinkAttrByID(linkID, key, value)
+	})
+}
+
+// DependsOn is Graph.DependsOn on the current snapshot
+func (s *SyncGraph[K]) DependsOn(child, parent K) bool {
+	return s.Snapshot().DependsOn(child, parent)
+}
+
+// HasDependent is Graph.HasDependent on the current snapshot
+func (s *SyncGraph[K]) HasDependent(parent, child K) bool {
+	return s.Snapshot().HasDependent(parent, child)
+}
+
+// SortedLayers is Graph.SortedLayers on the current snapshot
+func (s *SyncGraph[K]) SortedLayers() [][]K {
+	return s.Snapshot().SortedLayers()
+}
+
+// Sorted is Graph.Sorted on the current snapshot
+func (s *SyncGraph[K]) Sorted() []K {
+	return s.Snapshot().Sorted()
+}
+
+// TopologicalSort is Graph.TopologicalSort on the current snapshot
+func (s *SyncGraph[K]) TopologicalSort() []*TopologyOrder[K] {
+	return s.Snapshot().TopologicalSort()
+}
+
+// Copy returns a deep copy of the graph, changes to either don't affect the other
+func (g *Graph[K]) Copy() *Graph[K] {
+	c := &Graph[K]{
+		nodes:         make(nodeMap[K], len(g.nodes)),
+		dependencyMap: copyDepMap(g.dependencyMap),
+		dependentMap:  copyDepMap(g.dependentMap),
+		linkMap:       make(map[K]map[K][]*link, len(g.linkMap)),
+		linkIndex:     maps.Clone(g.linkIndex),
+		addCount:      g.addCount,
+		reach:         g.reach.copy(),
+		order:         g.order,
+	}
+	for id, n := range g.nodes {
+		nc := *n
+		nc.attrs = n.attrs.clone()
+		c.nodes[id] = &nc
+	}
+	for from, toLinkMap := range g.linkMap {
+		c.linkMap[from] = make(map[K][]*link, len(toLinkMap))
+		for to, links := range toLinkMap {
+			cl := make([]*link, len(links))
+			for i, l := range links {
+				cl[i] = &link{id: l.id, attrs: l.attrs.clone()}
+			}
+			c.linkMap[from][to] = cl
+		}
+	}
+	return c
+}
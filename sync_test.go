@@ -0,0 +1,72 @@
+package depgraph_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/timdadd/depgraph"
+)
+
+func TestSyncGraph(t *testing.T) {
+	s := depgraph.NewSyncGraph[string]()
+	assert.NoError(t, s.AddLink("1", "a", "b"))
+	before := s.Snapshot()
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			node := fmt.Sprintf("c%d", i)
+			assert.NoError(t, s.AddLink(fmt.Sprintf("l%d", i), "b", node))
+			assert.NoError(t, s.SetNodeAttr(node, depgraph.AttrLabel, node))
+		}()
+		go func() {
+			defer wg.Done()
+			g := s.Snapshot()
+			sorted := g.TopologicalSort()
+			assert.Len(t, sorted, len(g.Nodes()))
+			assert.True(t, s.DependsOn("b", "a"))
+		}()
+	}
+	wg.Wait()
+
+	assert.Len(t, s.Snapshot().Nodes(), 12)
+	assert.Len(t, s.TopologicalSort(), 12)
+	assert.True(t, s.DependsOn("c5", "a"))
+	label, _ := s.Snapshot().NodeAttr("c5", depgraph.AttrLabel)
+	assert.Equal(t, "c5", label)
+	// Earlier snapshots don't change
+	assert.Len(t, before.Nodes(), 2)
+
+	// A failed update leaves the graph alone
+	assert.Error(t, s.Update(func(g *depgraph.Graph[string]) error {
+		assert.NoError(t, g.RemoveNode("a"))
+		return g.DependOn("z", "z")
+	}))
+	assert.True(t, s.HasDependent("a", "b"))
+	assert.NoError(t, s.RemoveLink("a", "b"))
+	assert.False(t, s.HasDependent("a", "b"))
+	assert.NoError(t, s.RemoveNode("a"))
+	assert.Len(t, s.Sorted(), 11)
+}
+
+func TestCopy(t *testing.T) {
+	g := depgraph.NewGraph[string]()
+	assert.NoError(t, g.AddLink("1", "a", "b"))
+	assert.NoError(t, g.SetNodeAttr("a", depgraph.AttrLabel, "A"))
+	assert.NoError(t, g.SetLinkAttrByID("1", depgraph.AttrLabel, "One"))
+
+	c := g.Copy()
+	assert.NoError(t, c.SetNodeAttr("a", depgraph.AttrLabel, "Changed"))
+	assert.NoError(t, c.SetLinkAttrByID("1", depgraph.AttrLabel, "Changed"))
+	assert.NoError(t, c.AddLink("2", "b", "c"))
+
+	label, _ := g.NodeAttr("a", depgraph.AttrLabel)
+	assert.Equal(t, "A", label)
+	assert.Equal(t, depgraph.Attributes{depgraph.AttrLabel: "One"}, g.LinkAttrsByID("1"))
+	assert.Len(t, g.Nodes(), 2)
+	assert.Len(t, c.Nodes(), 3)
+}
@@ -0,0 +1,126 @@
+package depgraph
+
+import (
+	"slices"
+	"strings"
+)
+
+// StepNode is a step of a TopologicalSort with the branches that come off it
+type StepNode[K comparable] struct {
+	Order *TopologyOrder[K]
+	// Branch is the branch the step is on
+	Branch *StepBranch[K]
+	// Branches come off this step, the main route carries on with the next step on Branch
+	Branches []*StepBranch[K]
+	// Join is the step where the branches off this step come back together, see GatewayPairs
+	Join *StepNode[K]
+}
+
+// Parent is the step the branch this step is on comes off, nil on a root branch
+func (s *StepNode[K]) Parent() *StepNode[K] {
+	return s.Branch.Parent
+}
+
+// StepBranch is a run of steps sharing the same SortedStep prefix
+type StepBranch[K comparable] struct {
+	// Prefix is the SortedStep prefix of the steps, e.g. "0007.0002." for 7.2.1, 7.2.2
+	Prefix string
+	Steps  []*StepNode[K]
+	// Parent is the step the branch comes off, nil for a root branch, the main route or a lettered branch
+	Parent *StepNode[K]
+	// Join is the step of the node the branch leads back into, nil when the branch comes to an end
+	Join *StepNode[K]
+	// JoinLinkID is the first link from the last step of the branch to Join
+	JoinLinkID string
+}
+
+// TopologyTree rebuilds the branches of a TopologicalSort so they can be walked rather than parsed from
+// the steps.  The root branches, the main route and the lettered branches, are returned in order.  The
+// Join of a branch is the TopologyOrder.Join of its last step, the node the sort found already sorted.
+// The Join of a step with branches is the step of the join node GatewayPairs pairs it with
+func (g *Graph[K]) TopologyTree(order []*TopologyOrder[K]) []*StepBranch[K] {
+	roots := topologyTree(order)
+	steps := make(map[K]*StepNode[K], len(order))
+	var index func(branches []*StepBranch[K])
+	index = func(branches []*StepBranch[K]) {
+		for _, b := range branches {
+			for _, st := range b.Steps {
+				steps[st.Order.Node] = st
+				index(st.Branches)
+			}
+		}
+	}
+	index(roots)
+	var gj *gatewayJoins[K] // Only built if there are branches
+	var join func(branches []*StepBranch[K])
+	join = func(branches []*StepBranch[K]) {
+		for _, b := range branches {
+			for _, st := range b.Steps {
+				if len(st.Branches) == 0 {
+					continue
+				}
+				join(st.Branches)
+				if _, ok := g.nodes[st.Order.Node]; !ok {
+					continue
+				}
+				if gj == nil {
+					gj = g.gatewayJoins()
+				}
+				if j := gj.join(st.Order.Node); j != nil {
+					st.Join = steps[*j]
+				}
+			}
+			if last := b.Steps[len(b.Steps)-1]; last.Order.Join != nil {
+				b.Join, b.JoinLinkID = steps[*last.Order.Join], last.Order.JoinLinkID
+			}
+		}
+	}
+	join(roots)
+	return roots
+}
+
+// topologyTree rebuilds the branches of a TopologicalSort from the SortedStep prefixes.  A step "P.n"
+// is on the branch with prefix "P.", that branch comes off step "P" or, when there are several branches
+// off a step, "P" is "step.i" and it comes off "step".  Branches with no step to come off, the main route
+// and the lettered root branches, are returned in order
+func topologyTree[K comparable](order []*TopologyOrder[K]) (roots []*StepBranch[K]) {
+	sorted := slices.Clone(order)
+	slices.SortStableFunc(sorted, func(a, b *TopologyOrder[K]) int {
+		return strings.Compare(a.SortedStep, b.SortedStep)
+	})
+	steps := make(map[string]*StepNode[K], len(sorted))
+	branches := make(map[string]*StepBranch[K])
+	for _, to := range sorted {
+		prefix := to.SortedStep[:strings.LastIndex(to.SortedStep, ".")+1]
+		b, ok := branches[prefix]
+		if !ok {
+			b = &StepBranch[K]{Prefix: prefix, Parent: branchParent(steps, prefix)}
+			branches[prefix] = b
+			if b.Parent != nil {
+				b.Parent.Branches = append(b.Parent.Branches, b)
+			} else {
+				roots = append(roots, b)
+			}
+		}
+		st := &StepNode[K]{Order: to, Branch: b}
+		steps[to.SortedStep] = st
+		b.Steps = append(b.Steps, st)
+	}
+	return roots
+}
+
+// branchParent returns the step a branch with prefix comes off, nil for a root branch
+func branchParent[K comparable](steps map[string]*StepNode[K], prefix string) *StepNode[K] {
+	if prefix == "" {
+		return nil
+	}
+	parentStep := prefix[:len(prefix)-1]
+	if parent, ok := steps[parentStep]; ok {
+		return parent
+	}
+	// One of several branches, strip off the branch number
+	if i := strings.LastIndex(parentStep, "."); i >= 0 {
+		return steps[parentStep[:i]]
+	}
+	return nil
+}
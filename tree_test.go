@@ -0,0 +1,77 @@
+package depgraph_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/timdadd/depgraph"
+)
+
+func treeGraph(t *testing.T) *depgraph.Graph[string] {
+	g := depgraph.NewGraph[string]()
+	for _, l := range [][3]string{
+		{"1", "start", "split"},
+		{"2", "split", "a"},
+		{"3", "a", "b"},
+		{"4", "b", "join"},
+		{"5", "split", "c"},
+		{"6", "c", "join"},
+		{"7", "split", "d"},
+		{"8", "join", "end"},
+	} {
+		require.NoError(t, g.AddLink(l[0], l[1], l[2]))
+	}
+	g.AddNode("other", 10, 0)
+	return g
+}
+
+func TestTopologyTree(t *testing.T) {
+	g := treeGraph(t)
+	roots := g.TopologyTree(g.TopologicalSort())
+	require.Len(t, roots, 2)
+
+	stepNodes := func(b *depgraph.StepBranch[string]) (nodes []string) {
+		for _, st := range b.Steps {
+			nodes = append(nodes, st.Order.Step+" "+st.Order.Node)
+		}
+		return nodes
+	}
+	main := roots[0]
+	assert.Equal(t, []string{"1 start", "2 split", "3 a", "4 b", "5 join", "6 end"}, stepNodes(main))
+	assert.Nil(t, main.Parent)
+	assert.Nil(t, main.Join)
+	assert.Equal(t, []string{"A.1 other"}, stepNodes(roots[1]))
+
+	split := main.Steps[1]
+	require.Len(t, split.Branches, 2)
+	c, d := split.Branches[0], split.Branches[1]
+	assert.Equal(t, []string{"2.1.1 c"}, stepNodes(c))
+	assert.Equal(t, []string{"2.2.1 d"}, stepNodes(d))
+	assert.Same(t, split, c.Parent)
+	assert.Same(t, split, c.Steps[0].Parent())
+	assert.Same(t, c, c.Steps[0].Branch)
+	assert.Same(t, main.Steps[4], c.Join)
+	assert.Equal(t, "6", c.JoinLinkID)
+	assert.Nil(t, d.Join)
+	assert.Empty(t, d.JoinLinkID)
+	assert.Nil(t, split.Parent())
+	// d never joins the other branches
+	assert.Nil(t, split.Join)
+
+	require.NoError(t, g.AddLink("9", "d", "end"))
+	roots = g.TopologyTree(g.TopologicalSort())
+	split = roots[0].Steps[1]
+	require.NotNil(t, split.Join)
+	assert.Equal(t, "end", split.Join.Order.Node)
+	assert.Equal(t, "9", split.Branches[1].JoinLinkID)
+
+	// Joins are where the sort found the route, not where the graph goes now
+	order := g.TopologicalSort()
+	require.NoError(t, g.RemoveLink("c", "join"))
+	roots = g.TopologyTree(order)
+	c = roots[0].Steps[1].Branches[0]
+	require.NotNil(t, c.Join)
+	assert.Equal(t, "join", c.Join.Order.Node)
+	assert.Equal(t, "6", c.JoinLinkID)
+}